@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultGitLabHost is used when the user doesn't pass --hostname.
+const defaultGitLabHost = "gitlab.com"
+
+// HostAuth is one host's stored credential in hosts.yml.
+type HostAuth struct {
+	Token string `yaml:"token"`
+}
+
+// HostsConfig is the on-disk shape of ~/.config/prismlinux/repository/hosts.yml.
+type HostsConfig struct {
+	Hosts map[string]HostAuth `yaml:"hosts"`
+}
+
+// hostsConfigPath is where per-user GitLab credentials are persisted by
+// `auth login`, outside of the repo so it never ends up in version control.
+func hostsConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "prismlinux", "repository", "hosts.yml"), nil
+}
+
+func loadHostsConfig() (*HostsConfig, error) {
+	path, err := hostsConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &HostsConfig{Hosts: make(map[string]HostAuth)}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var cfg HostsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if cfg.Hosts == nil {
+		cfg.Hosts = make(map[string]HostAuth)
+	}
+	return &cfg, nil
+}
+
+// save writes hosts.yml with 0600 permissions, since it holds a bearer token.
+func (hc *HostsConfig) save() error {
+	path, err := hostsConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	data, err := yaml.Marshal(hc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hosts config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// storedGitLabToken returns the token persisted for host by `auth login`, if
+// any. Used by NewConfig as a fallback behind --gitlab-token/GITLAB_TOKEN.
+func storedGitLabToken(host string) (string, bool) {
+	hc, err := loadHostsConfig()
+	if err != nil {
+		return "", false
+	}
+	auth, ok := hc.Hosts[host]
+	if !ok || auth.Token == "" {
+		return "", false
+	}
+	return auth.Token, true
+}
+
+// readTokenInteractively reads a GitLab token from stdin: without echo when
+// stdin is a TTY, or a plain buffered line otherwise (e.g. piped into the
+// command in a script).
+func readTokenInteractively() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Print("Paste your GitLab personal access token: ")
+		tokenBytes, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		return strings.TrimRight(string(tokenBytes), "\r\n"), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// verifyGitLabToken confirms token is accepted by host and returns the
+// authenticated username.
+func verifyGitLabToken(host, token string) (string, error) {
+	client, err := gitlab.NewClient(token, gitlab.WithBaseURL("https://"+host+"/api/v4"))
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitLab client: %w", err)
+	}
+
+	user, _, err := client.Users.CurrentUser()
+	if err != nil {
+		return "", NewAuthError(fmt.Sprintf("token was rejected by %s", host), err)
+	}
+	return user.Username, nil
+}
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage GitLab authentication for this CLI",
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to a GitLab host and store the token",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host := getStringFlag(cmd, "hostname", defaultGitLabHost)
+
+		token, err := readTokenInteractively()
+		if err != nil {
+			return err
+		}
+		if token == "" {
+			return fmt.Errorf("no token provided")
+		}
+
+		username, err := verifyGitLabToken(host, token)
+		if err != nil {
+			return err
+		}
+
+		hc, err := loadHostsConfig()
+		if err != nil {
+			return err
+		}
+		hc.Hosts[host] = HostAuth{Token: token}
+		if err := hc.save(); err != nil {
+			return err
+		}
+
+		fmt.Printf("Logged in to %s as %s\n", host, username)
+		return nil
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the active GitLab host and token validity",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host := getStringFlag(cmd, "hostname", defaultGitLabHost)
+
+		if envToken := os.Getenv("GITLAB_TOKEN"); envToken != "" {
+			username, err := verifyGitLabToken(host, envToken)
+			if err != nil {
+				fmt.Printf("%s: GITLAB_TOKEN is set but invalid: %v\n", host, err)
+				return nil
+			}
+			fmt.Printf("%s: logged in as %s via GITLAB_TOKEN\n", host, username)
+			return nil
+		}
+
+		hc, err := loadHostsConfig()
+		if err != nil {
+			return err
+		}
+		auth, ok := hc.Hosts[host]
+		if !ok || auth.Token == "" {
+			fmt.Printf("%s: not logged in\n", host)
+			return nil
+		}
+
+		username, err := verifyGitLabToken(host, auth.Token)
+		if err != nil {
+			fmt.Printf("%s: stored token is invalid: %v\n", host, err)
+			return nil
+		}
+		fmt.Printf("%s: logged in as %s\n", host, username)
+		return nil
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored token for a GitLab host",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host := getStringFlag(cmd, "hostname", defaultGitLabHost)
+
+		hc, err := loadHostsConfig()
+		if err != nil {
+			return err
+		}
+		if _, ok := hc.Hosts[host]; !ok {
+			fmt.Printf("%s: not logged in\n", host)
+			return nil
+		}
+
+		delete(hc.Hosts, host)
+		if err := hc.save(); err != nil {
+			return err
+		}
+
+		fmt.Printf("Logged out of %s\n", host)
+		return nil
+	},
+}
+
+func init() {
+	authLoginCmd.Flags().String("hostname", defaultGitLabHost, "GitLab host to log in to")
+	authStatusCmd.Flags().String("hostname", defaultGitLabHost, "GitLab host to check")
+	authLogoutCmd.Flags().String("hostname", defaultGitLabHost, "GitLab host to log out of")
+
+	authCmd.AddCommand(authLoginCmd, authStatusCmd, authLogoutCmd)
+	RootCmd.AddCommand(authCmd)
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	gitHTTP "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateResult is one project's comparison between its pinned release and
+// the latest one available on GitLab, plus the MR (if any) opened to bump it.
+type UpdateResult struct {
+	Project string `json:"project"`
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+	MRURL   string `json:"mr_url,omitempty"`
+}
+
+// latestReleaseTag returns the tag of the most recently created release for
+// projectID, or "" if the project has none.
+func (pm *PackageManager) latestReleaseTag(projectID string) (string, error) {
+	releases, _, err := pm.gitlabClient.Releases.ListReleases(projectID, &gitlab.ListReleasesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list releases for %s: %w", projectID, err)
+	}
+	if len(releases) == 0 {
+		return "", nil
+	}
+
+	sortReleasesByDate(releases)
+	return releases[0].TagName, nil
+}
+
+// checkForUpdates compares every enabled GitLabProject's pinned_tag against
+// its latest GitLab release. Projects that are behind are reported, and
+// unless dryRun is set, a merge request bumping pinned_tag is opened against
+// configProjectID.
+func (pm *PackageManager) checkForUpdates(packagesConfig *PackagesConfig, configProjectID, targetBranch, assignee string, dryRun bool) ([]UpdateResult, error) {
+	if pm.gitlabClient == nil {
+		return nil, NewAuthError("a GitLab token is required to check for updates", nil)
+	}
+
+	var results []UpdateResult
+
+	for i := range packagesConfig.GitLabProjects {
+		project := &packagesConfig.GitLabProjects[i]
+		if !project.Enabled {
+			continue
+		}
+
+		latest, err := pm.latestReleaseTag(project.ID)
+		if err != nil {
+			pm.config.debugLog("Failed to check updates for %s: %v", project.Name, err)
+			continue
+		}
+		if latest == "" || latest == project.PinnedTag {
+			continue
+		}
+
+		result := UpdateResult{Project: project.Name, Current: project.PinnedTag, Latest: latest}
+
+		if dryRun {
+			pm.config.verboseLog("Would open update MR for %s: %s -> %s", project.Name, project.PinnedTag, latest)
+		} else if configProjectID == "" {
+			pm.config.debugLog("No --config-project-id given, skipping MR for %s", project.Name)
+		} else {
+			mrURL, err := pm.openUpdateMR(configProjectID, project, latest, targetBranch, assignee)
+			if err != nil {
+				pm.config.debugLog("Failed to open update MR for %s: %v", project.Name, err)
+			} else {
+				result.MRURL = mrURL
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Project < results[j].Project })
+	return results, nil
+}
+
+// openUpdateMR clones the repo hosting packages_config.yaml into a temp
+// directory, bumps project's pinned_tag to newTag on a new branch, pushes it,
+// and opens a merge request back against targetBranch.
+func (pm *PackageManager) openUpdateMR(configProjectID string, project *GitLabProject, newTag, targetBranch, assignee string) (string, error) {
+	gitProject, _, err := pm.gitlabClient.Projects.GetProject(configProjectID, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up config project %s: %w", configProjectID, err)
+	}
+
+	workDir, err := os.MkdirTemp("", "repository-check-updates-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp clone dir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	auth := &gitHTTP.BasicAuth{Username: "oauth2", Password: pm.config.GitLabToken}
+
+	repo, err := git.PlainClone(workDir, false, &git.CloneOptions{
+		URL:           gitProject.HTTPURLToRepo,
+		Auth:          auth,
+		ReferenceName: plumbing.NewBranchReferenceName(targetBranch),
+		SingleBranch:  true,
+		Depth:         1,
+	})
+	if err != nil {
+		return "", NewNetworkError(fmt.Sprintf("failed to clone %s", gitProject.HTTPURLToRepo), err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	branchName := fmt.Sprintf("update-%s-%s", project.Name, newTag)
+	if err := worktree.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(branchName),
+		Create: true,
+	}); err != nil {
+		return "", fmt.Errorf("failed to create branch %s: %w", branchName, err)
+	}
+
+	configPath := workDir + "/packages_config.yaml"
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	var cloned PackagesConfig
+	if err := yaml.Unmarshal(data, &cloned); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+
+	found := false
+	for i := range cloned.GitLabProjects {
+		if cloned.GitLabProjects[i].ID == project.ID {
+			cloned.GitLabProjects[i].PinnedTag = newTag
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("project %s (%s) not found in cloned %s", project.Name, project.ID, configPath)
+	}
+
+	out, err := yaml.Marshal(&cloned)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal updated config: %w", err)
+	}
+	if err := os.WriteFile(configPath, out, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", configPath, err)
+	}
+
+	if _, err := worktree.Add("packages_config.yaml"); err != nil {
+		return "", fmt.Errorf("failed to stage %s: %w", configPath, err)
+	}
+
+	commitMsg := fmt.Sprintf("Bump %s to %s", project.Name, newTag)
+	if _, err := worktree.Commit(commitMsg, &git.CommitOptions{
+		Author: &object.Signature{Name: "repository-bot", Email: "repository-bot@prismlinux.org"},
+	}); err != nil {
+		return "", fmt.Errorf("failed to commit: %w", err)
+	}
+
+	if err := repo.Push(&git.PushOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branchName, branchName))},
+		Auth:     auth,
+	}); err != nil {
+		return "", fmt.Errorf("failed to push %s: %w", branchName, err)
+	}
+
+	mrOpts := &gitlab.CreateMergeRequestOptions{
+		Title:        gitlab.Ptr(commitMsg),
+		Description:  gitlab.Ptr(fmt.Sprintf("Automated update: %s %s -> %s", project.Name, project.PinnedTag, newTag)),
+		SourceBranch: gitlab.Ptr(branchName),
+		TargetBranch: gitlab.Ptr(targetBranch),
+	}
+	if assignee != "" {
+		users, _, err := pm.gitlabClient.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.Ptr(assignee)})
+		if err == nil && len(users) > 0 {
+			mrOpts.AssigneeIDs = &[]int64{users[0].ID}
+		}
+	}
+
+	mr, _, err := pm.gitlabClient.MergeRequests.CreateMergeRequest(configProjectID, mrOpts)
+	if err != nil {
+		return "", fmt.Errorf("failed to open merge request: %w", err)
+	}
+
+	project.PinnedTag = newTag
+	return mr.WebURL, nil
+}
+
+var checkUpdatesCmd = &cobra.Command{
+	Use:   "check-updates",
+	Short: "Check GitLab projects for new releases and open MRs bumping pinned_tag",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := NewConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		packagesConfig, err := readPackagesConfig()
+		if err != nil {
+			return fmt.Errorf("failed to read packages configuration: %w", err)
+		}
+
+		pm, err := NewPackageManager(cfg)
+		if err != nil {
+			return err
+		}
+		if pm.store != nil {
+			defer pm.store.Close()
+		}
+
+		configProjectID := getStringFlag(cmd, "config-project-id", "")
+		targetBranch := getStringFlag(cmd, "target-branch", "main")
+		assignee := getStringFlag(cmd, "assignee", "")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		results, err := pm.checkForUpdates(packagesConfig, configProjectID, targetBranch, assignee, dryRun)
+		if err != nil {
+			return err
+		}
+
+		out, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal updates.json: %w", err)
+		}
+		if err := os.WriteFile("updates.json", out, 0644); err != nil {
+			return fmt.Errorf("failed to write updates.json: %w", err)
+		}
+
+		cfg.infoLog("Found %d project(s) with newer releases available", len(results))
+		return nil
+	},
+}
+
+func init() {
+	checkUpdatesCmd.Flags().String("repo-name", stringFlagDefault("repo-name", "prismlinux"), "Repository name")
+	checkUpdatesCmd.Flags().String("arch", stringFlagDefault("arch", "x86_64"), "Target architecture")
+	checkUpdatesCmd.Flags().String("api-dir", stringFlagDefault("api-dir", "api"), "API directory for metadata")
+	checkUpdatesCmd.Flags().String("config-project-id", "", "GitLab project ID hosting packages_config.yaml")
+	checkUpdatesCmd.Flags().String("target-branch", "main", "Branch to open update merge requests against")
+	checkUpdatesCmd.Flags().String("assignee", "", "Username to assign opened merge requests to")
+	checkUpdatesCmd.Flags().Bool("dry-run", false, "Report available updates without opening merge requests")
+
+	RootCmd.AddCommand(checkUpdatesCmd)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/prismlinux/repository/internal/testhelper"
+)
+
+func TestCleanRemovesPackagesAndResetsPackagesJSON(t *testing.T) {
+	bin := testhelper.BuildCLI(t)
+	dir := t.TempDir()
+
+	archDir := filepath.Join(dir, "x86_64")
+	apiDir := filepath.Join(dir, "api")
+	if err := os.MkdirAll(archDir, 0755); err != nil {
+		t.Fatalf("failed to create repo-arch-dir: %v", err)
+	}
+	if err := os.MkdirAll(apiDir, 0755); err != nil {
+		t.Fatalf("failed to create api-dir: %v", err)
+	}
+
+	pkgPath := filepath.Join(archDir, "example-1.0.0-1-x86_64.pkg.tar.zst")
+	if err := os.WriteFile(pkgPath, []byte("fake package"), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", pkgPath, err)
+	}
+	dbPath := filepath.Join(archDir, "prismlinux.db.tar.gz")
+	if err := os.WriteFile(dbPath, []byte("fake db"), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", dbPath, err)
+	}
+	apiFile := filepath.Join(apiDir, "stable-x86_64.json")
+	if err := os.WriteFile(apiFile, []byte(`[{"name":"example"}]`), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", apiFile, err)
+	}
+
+	result := testhelper.Run(t, bin, dir, withIsolatedHome(t),
+		"clean", "--repo-name", "prismlinux", "--arch", "x86_64", "--api-dir", "api")
+	if result.ExitCode != 0 {
+		t.Fatalf("clean exited %d, stderr:\n%s", result.ExitCode, result.Stderr)
+	}
+
+	if _, err := os.Stat(pkgPath); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, stat err: %v", pkgPath, err)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, stat err: %v", dbPath, err)
+	}
+
+	data, err := os.ReadFile(apiFile)
+	if err != nil {
+		t.Fatalf("expected %s to be recreated as an empty packages.json, got: %v", apiFile, err)
+	}
+	if string(data) != "[]" {
+		t.Errorf("expected %s to contain an empty array, got %q", apiFile, string(data))
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// knownArchitectures seeds --arch completion; packageArch-derived values
+// found on disk are added on top so an unusual arch already in the repo
+// still completes.
+var knownArchitectures = []string{"x86_64", "aarch64", "armv7h", "any"}
+
+var completionCmd = &cobra.Command{
+	Use:                   "completion [bash|zsh|fish|powershell]",
+	Short:                 "Generate a shell completion script",
+	DisableFlagsInUseLine: true,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return cmd.Root().GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return cmd.Root().GenZshCompletion(os.Stdout)
+		case "fish":
+			return cmd.Root().GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			return cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+		return nil
+	},
+}
+
+// archCompletionFunc completes --arch from knownArchitectures plus any
+// architecture directory actually present next to the current one.
+func archCompletionFunc(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	archSet := make(map[string]bool)
+	var arches []string
+	for _, arch := range knownArchitectures {
+		if archSet[arch] {
+			continue
+		}
+		archSet[arch] = true
+		arches = append(arches, arch)
+	}
+
+	for _, dir := range []string{".", "testing"} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && !archSet[entry.Name()] {
+				archSet[entry.Name()] = true
+				arches = append(arches, entry.Name())
+			}
+		}
+	}
+
+	sort.Strings(arches)
+	return filterByPrefix(arches, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// repoNameCompletionFunc completes --repo-name by recovering dbBaseName
+// values from "*.db.tar.gz" files already on disk (see Config.getDBName),
+// falling back to the built-in default when nothing has synced yet.
+func repoNameCompletionFunc(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	seen := make(map[string]bool)
+	var names []string
+
+	var archDirs []string
+	if entries, err := os.ReadDir("."); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				archDirs = append(archDirs, entry.Name(), filepath.Join("testing", entry.Name()))
+			}
+		}
+	}
+
+	for _, dir := range archDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.Name(), ".db.tar.gz") {
+				continue
+			}
+			name := strings.TrimSuffix(strings.TrimSuffix(entry.Name(), ".db.tar.gz"), "-testing")
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		names = []string{"prismlinux"}
+	}
+
+	sort.Strings(names)
+	return filterByPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// packageNameCompletionFunc completes a package-name positional argument
+// (e.g. `history <package>`) from whatever is already in the resolved
+// repo-arch-dir, stripping each filename down to its bare package name.
+func packageNameCompletionFunc(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := NewConfig(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	entries, err := os.ReadDir(cfg.RepoArchDir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".pkg.tar.zst") {
+			continue
+		}
+		base := strings.TrimSuffix(entry.Name(), ".pkg.tar.zst")
+		parts := strings.Split(base, "-")
+		if len(parts) < 4 {
+			continue
+		}
+		name := strings.Join(parts[:len(parts)-3], "-")
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return filterByPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func filterByPrefix(values []string, prefix string) []string {
+	if prefix == "" {
+		return values
+	}
+	var matched []string
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			matched = append(matched, value)
+		}
+	}
+	return matched
+}
+
+// registerDynamicCompletions wires the completion functions above onto
+// every flag/arg that already exists across RootCmd's subcommands. Called
+// once from main after all commands have registered their flags in init().
+func registerDynamicCompletions(root *cobra.Command) {
+	candidates := append([]*cobra.Command{root}, root.Commands()...)
+	for _, cmd := range candidates {
+		if cmd.Flags().Lookup("arch") != nil {
+			_ = cmd.RegisterFlagCompletionFunc("arch", archCompletionFunc)
+		}
+		if cmd.Flags().Lookup("repo-name") != nil {
+			_ = cmd.RegisterFlagCompletionFunc("repo-name", repoNameCompletionFunc)
+		}
+	}
+
+	historyCmd.ValidArgsFunction = packageNameCompletionFunc
+}
+
+func init() {
+	RootCmd.AddCommand(completionCmd)
+}
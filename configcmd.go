@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"gitlab.com/prismlinux/repository/settings"
+)
+
+type settingsContextKey struct{}
+
+// settingsFromContext returns the Settings loaded by RootCmd's
+// PersistentPreRunE, or nil if it hasn't run (e.g. in tests that call a
+// RunE directly).
+func settingsFromContext(ctx context.Context) *settings.Settings {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(settingsContextKey{}).(*settings.Settings)
+	return s
+}
+
+var (
+	flagDefaultsOnce   sync.Once
+	loadedFlagDefaults *settings.Settings
+)
+
+// flagDefaults loads the same user/project config PersistentPreRunE loads,
+// once per process, so every subcommand's init() can seed its --repo-name,
+// --arch, etc. flag defaults from it instead of a hardcoded literal. It runs
+// at flag-registration time (before PersistentPreRunE), which is fine here:
+// the config it reads can't change mid-process.
+func flagDefaults() *settings.Settings {
+	flagDefaultsOnce.Do(func() {
+		loadedFlagDefaults, _ = settings.Load()
+	})
+	return loadedFlagDefaults
+}
+
+// stringFlagDefault returns key's configured value to register as a flag's
+// default, or fallback if it isn't set.
+func stringFlagDefault(key, fallback string) string {
+	if s := flagDefaults(); s != nil {
+		if value, ok := s.Get(key); ok {
+			return value
+		}
+	}
+	return fallback
+}
+
+// boolFlagDefault is stringFlagDefault for bool-valued keys.
+func boolFlagDefault(key string, fallback bool) bool {
+	if s := flagDefaults(); s != nil {
+		if value, ok := s.Get(key); ok {
+			if parsed, err := strconv.ParseBool(value); err == nil {
+				return parsed
+			}
+		}
+	}
+	return fallback
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage persistent CLI defaults (~/.config/prismlinux/repository/config.yml)",
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the resolved value of a config key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		merged, err := settings.Load()
+		if err != nil {
+			return err
+		}
+
+		value, ok := merged.Get(args[0])
+		if !ok {
+			return fmt.Errorf("%s is not set", args[0])
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Persist a config key to the user-level config file",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := settings.LoadUser()
+		if err != nil {
+			return err
+		}
+
+		if err := s.Set(args[0], args[1]); err != nil {
+			return err
+		}
+
+		if err := s.Save(); err != nil {
+			return err
+		}
+
+		path, _ := settings.UserConfigPath()
+		fmt.Printf("Set %s in %s\n", args[0], path)
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print every resolved config key/value",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		merged, err := settings.Load()
+		if err != nil {
+			return err
+		}
+
+		keys := settings.Keys()
+		sort.Strings(keys)
+		for _, key := range keys {
+			if value, ok := merged.Get(key); ok {
+				fmt.Printf("%s=%s\n", key, value)
+			}
+		}
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the user-level config file in $EDITOR",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := settings.UserConfigPath()
+		if err != nil {
+			return err
+		}
+
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := (&settings.Settings{}).Save(); err != nil {
+				return err
+			}
+		}
+
+		editor := os.Getenv("EDITOR")
+		if editor == "" {
+			editor = "vi"
+		}
+
+		editCmd := exec.Command(editor, path)
+		editCmd.Stdin = os.Stdin
+		editCmd.Stdout = os.Stdout
+		editCmd.Stderr = os.Stderr
+		return editCmd.Run()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd, configEditCmd)
+	RootCmd.AddCommand(configCmd)
+
+	RootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		loaded, err := settings.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		cmd.SetContext(context.WithValue(cmd.Context(), settingsContextKey{}, loaded))
+
+		maybeNotifyUpdate(cmd)
+		return nil
+	}
+}
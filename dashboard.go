@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// dashboardPackage is one row in the rendered dashboard, combining
+// PackageInfo with a derived status for the most recent sync run.
+type dashboardPackage struct {
+	PackageInfo
+	Status string
+}
+
+// dashboardGroup is a source project (or remote host) and the packages it
+// contributed, across both the stable and testing repos.
+type dashboardGroup struct {
+	Source   string
+	Packages []dashboardPackage
+}
+
+type dashboardCounters struct {
+	Total   int
+	Added   int
+	Removed int
+	Failed  int
+}
+
+type dashboardData struct {
+	Generated string
+	Counters  dashboardCounters
+	Groups    []dashboardGroup
+}
+
+const defaultIndexTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>PrismLinux Repository Status</title>
+</head>
+<body>
+<h1>PrismLinux Repository Status</h1>
+<p>Generated: {{.Generated}}</p>
+<ul>
+<li>Total packages: {{.Counters.Total}}</li>
+<li>Added this run: {{.Counters.Added}}</li>
+<li>Removed this run: {{.Counters.Removed}}</li>
+<li>Failed this run: {{.Counters.Failed}}</li>
+</ul>
+{{range .Groups}}
+<h2>{{.Source}}</h2>
+<table border="1" cellpadding="4">
+<tr><th>Name</th><th>Version</th><th>Arch</th><th>Size</th><th>Modified</th><th>Status</th></tr>
+{{range .Packages}}
+<tr>
+<td><a href="pkg/{{.Filename}}.html">{{.Name}}</a></td>
+<td>{{.Version}}</td>
+<td>{{.Architecture}}</td>
+<td>{{.Size}}</td>
+<td>{{.Modified}}</td>
+<td>{{.Status}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`
+
+const defaultPackageTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} {{.Version}}</title>
+</head>
+<body>
+<h1>{{.Name}} {{.Version}}</h1>
+<ul>
+<li>Architecture: {{.Architecture}}</li>
+<li>Repository: {{.Repository}}</li>
+<li>Size: {{.Size}}</li>
+<li>Modified: {{.Modified}}</li>
+<li>Signed: {{.Signed}}</li>
+<li>Status: {{.Status}}</li>
+{{if .SourceURL}}<li>Source: <a href="{{.SourceURL}}">{{.SourceURL}}</a></li>{{end}}
+</ul>
+<p>{{.Description}}</p>
+</body>
+</html>
+`
+
+// generateDashboard walks the stable and testing API metadata for every
+// architecture in arches, groups packages by source project (GitLab project
+// name or remote URL host), and renders a static HTML site into
+// APIDir/html/.
+func generateDashboard(cfg *Config, templateDir string, arches []string) error {
+	indexTmpl, pkgTmpl, err := loadDashboardTemplates(templateDir)
+	if err != nil {
+		return err
+	}
+
+	state, err := loadDownloadState(cfg)
+	if err != nil {
+		return err
+	}
+
+	groupsBySource := make(map[string][]dashboardPackage)
+	var counters dashboardCounters
+
+	if len(arches) == 0 {
+		arches = []string{cfg.Architecture}
+	}
+
+	for _, target := range []string{"stable", "testing"} {
+		for _, arch := range arches {
+			summary := state.LastRun[target+"-"+arch]
+			counters.Added += len(summary.Added)
+			counters.Removed += len(summary.Removed)
+			counters.Failed += len(summary.Failed)
+
+			failed := make(map[string]bool)
+			for _, name := range summary.Failed {
+				failed[name] = true
+			}
+
+			apiPath := filepath.Join(cfg.APIDir, fmt.Sprintf("%s-%s.json", target, arch))
+			data, err := os.ReadFile(apiPath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return fmt.Errorf("failed to read %s: %w", apiPath, err)
+			}
+
+			var packages []PackageInfo
+			if err := json.Unmarshal(data, &packages); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", apiPath, err)
+			}
+
+			for _, pkg := range packages {
+				status := "ok"
+				switch {
+				case failed[pkg.Filename]:
+					status = "download-failed"
+				case (cfg.RequireSignatures || cfg.SigningKey != "") && !pkg.Signed:
+					status = "signature-missing"
+				}
+
+				source := pkg.Source
+				if source == "" {
+					source = "unknown"
+				}
+
+				groupsBySource[source] = append(groupsBySource[source], dashboardPackage{PackageInfo: pkg, Status: status})
+				counters.Total++
+			}
+
+			// removeOrphanedPackages already deleted these from disk and
+			// packages.json, so there's no PackageInfo left to look up;
+			// surface what we still know (the filename) under a synthetic
+			// group rather than silently dropping them from the dashboard.
+			for _, filename := range summary.Removed {
+				groupsBySource["Recently removed"] = append(groupsBySource["Recently removed"], dashboardPackage{
+					PackageInfo: PackageInfo{Filename: filename, Repository: target, Architecture: arch},
+					Status:      "orphaned-last-run",
+				})
+			}
+		}
+	}
+
+	var sources []string
+	for source := range groupsBySource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	var groups []dashboardGroup
+	for _, source := range sources {
+		groups = append(groups, dashboardGroup{Source: source, Packages: groupsBySource[source]})
+	}
+
+	data := dashboardData{
+		Generated: time.Now().Format("2006-01-02 15:04:05 MST"),
+		Counters:  counters,
+		Groups:    groups,
+	}
+
+	htmlDir := filepath.Join(cfg.APIDir, "html")
+	pkgDir := filepath.Join(htmlDir, "pkg")
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		return fmt.Errorf("failed to create dashboard directory %s: %w", htmlDir, err)
+	}
+
+	indexFile, err := os.Create(filepath.Join(htmlDir, "index.html"))
+	if err != nil {
+		return fmt.Errorf("failed to create dashboard index: %w", err)
+	}
+	defer indexFile.Close()
+
+	if err := indexTmpl.Execute(indexFile, data); err != nil {
+		return fmt.Errorf("failed to render dashboard index: %w", err)
+	}
+
+	for _, group := range groups {
+		for _, pkg := range group.Packages {
+			pkgFile, err := os.Create(filepath.Join(pkgDir, pkg.Filename+".html"))
+			if err != nil {
+				return fmt.Errorf("failed to create package page for %s: %w", pkg.Filename, err)
+			}
+			err = pkgTmpl.Execute(pkgFile, pkg)
+			pkgFile.Close()
+			if err != nil {
+				return fmt.Errorf("failed to render package page for %s: %w", pkg.Filename, err)
+			}
+		}
+	}
+
+	cfg.infoLog("Generated dashboard with %d packages across %d sources", counters.Total, len(groups))
+	return nil
+}
+
+func loadDashboardTemplates(templateDir string) (*template.Template, *template.Template, error) {
+	if templateDir == "" {
+		return template.Must(template.New("index").Parse(defaultIndexTemplate)),
+			template.Must(template.New("package").Parse(defaultPackageTemplate)),
+			nil
+	}
+
+	indexTmpl, err := template.ParseFiles(filepath.Join(templateDir, "index.html.tmpl"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load index template from %s: %w", templateDir, err)
+	}
+
+	pkgTmpl, err := template.ParseFiles(filepath.Join(templateDir, "package.html.tmpl"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load package template from %s: %w", templateDir, err)
+	}
+
+	return indexTmpl, pkgTmpl, nil
+}
+
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "Render a static HTML status dashboard alongside the JSON API",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := NewConfig(cmd)
+		if err != nil {
+			return err
+		}
+		templateDir, _ := cmd.Flags().GetString("template-dir")
+
+		packagesConfig, _ := readPackagesConfig()
+		var configuredArches []string
+		if packagesConfig != nil {
+			configuredArches = packagesConfig.Architectures
+		}
+		arches := resolveArchMatrix(cmd, configuredArches)
+
+		return generateDashboard(cfg, templateDir, arches)
+	},
+}
+
+func init() {
+	dashboardCmd.Flags().String("repo-name", stringFlagDefault("repo-name", "prismlinux"), "Repository name")
+	dashboardCmd.Flags().String("arch", stringFlagDefault("arch", "x86_64"), "Target architecture")
+	dashboardCmd.Flags().String("arches", "", "Comma-separated architecture matrix to include in the dashboard")
+	dashboardCmd.Flags().String("repo-arch-dir", "", "Architecture-specific repo directory (auto-determined)")
+	dashboardCmd.Flags().String("api-dir", stringFlagDefault("api-dir", "api"), "API directory for metadata")
+	dashboardCmd.Flags().Bool("testing", boolFlagDefault("testing", false), "Render dashboard for the testing repository")
+	dashboardCmd.Flags().String("template-dir", "", "Directory with index.html.tmpl/package.html.tmpl overrides")
+
+	RootCmd.AddCommand(dashboardCmd)
+}
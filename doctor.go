@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// CheckStatus is the outcome of a single doctor check.
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckFail CheckStatus = "fail"
+	CheckSkip CheckStatus = "skip"
+)
+
+// CheckResult is one preflight check's name, outcome, and a human-readable
+// detail (a version string on pass, the error on fail).
+type CheckResult struct {
+	Name   string      `json:"name"`
+	Status CheckStatus `json:"status"`
+	Detail string      `json:"detail,omitempty"`
+}
+
+// requiredBinaries are the external tools the sync/signing/dashboard paths
+// shell out to.
+var requiredBinaries = []string{"repo-add", "repo-remove", "gpg", "zstd", "xz"}
+
+// runDoctorChecks fans out the independent environment checks and collects
+// their results; it never returns an error itself, so the caller can always
+// render a full report before deciding on the exit code.
+func runDoctorChecks(cfg *Config) []CheckResult {
+	var results []CheckResult
+
+	for _, bin := range requiredBinaries {
+		results = append(results, checkBinaryOnPath(bin))
+	}
+
+	results = append(results, checkRepoArchDirWritable(cfg))
+	results = append(results, checkAPIDir(cfg))
+	results = append(results, checkGitLabToken(cfg))
+	results = append(results, checkSigningKey(cfg))
+
+	return results
+}
+
+func checkBinaryOnPath(name string) CheckResult {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return CheckResult{Name: "binary:" + name, Status: CheckFail, Detail: "not found on PATH"}
+	}
+
+	out, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return CheckResult{Name: "binary:" + name, Status: CheckPass, Detail: path}
+	}
+
+	firstLine := strings.SplitN(strings.TrimSpace(string(out)), "\n", 2)[0]
+	return CheckResult{Name: "binary:" + name, Status: CheckPass, Detail: firstLine}
+}
+
+func checkRepoArchDirWritable(cfg *Config) CheckResult {
+	if err := os.MkdirAll(cfg.RepoArchDir, 0755); err != nil {
+		return CheckResult{Name: "repo-arch-dir", Status: CheckFail, Detail: fmt.Sprintf("%s: %v", cfg.RepoArchDir, err)}
+	}
+
+	probe := filepath.Join(cfg.RepoArchDir, ".doctor-write-test")
+	if err := os.WriteFile(probe, []byte("ok"), 0644); err != nil {
+		return CheckResult{Name: "repo-arch-dir", Status: CheckFail, Detail: fmt.Sprintf("%s is not writable: %v", cfg.RepoArchDir, err)}
+	}
+	os.Remove(probe)
+
+	return CheckResult{Name: "repo-arch-dir", Status: CheckPass, Detail: cfg.RepoArchDir}
+}
+
+func checkAPIDir(cfg *Config) CheckResult {
+	info, err := os.Stat(cfg.APIDir)
+	if os.IsNotExist(err) {
+		return CheckResult{Name: "api-dir", Status: CheckPass, Detail: cfg.APIDir + " does not exist yet, will be created"}
+	}
+	if err != nil {
+		return CheckResult{Name: "api-dir", Status: CheckFail, Detail: err.Error()}
+	}
+	if !info.IsDir() {
+		return CheckResult{Name: "api-dir", Status: CheckFail, Detail: cfg.APIDir + " exists but is not a directory"}
+	}
+
+	entries, err := os.ReadDir(cfg.APIDir)
+	if err != nil {
+		return CheckResult{Name: "api-dir", Status: CheckFail, Detail: fmt.Sprintf("failed to read %s: %v", cfg.APIDir, err)}
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(cfg.APIDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var packages []PackageInfo
+		if err := json.Unmarshal(data, &packages); err != nil {
+			return CheckResult{Name: "api-dir", Status: CheckFail, Detail: fmt.Sprintf("%s is not valid package metadata: %v", entry.Name(), err)}
+		}
+	}
+
+	return CheckResult{Name: "api-dir", Status: CheckPass, Detail: cfg.APIDir}
+}
+
+// checkGitLabToken requires both the "api" and "read_repository" scopes,
+// since sync needs read_repository for release assets and check-updates
+// needs api for merge request creation.
+func checkGitLabToken(cfg *Config) CheckResult {
+	if cfg.GitLabToken == "" {
+		return CheckResult{Name: "gitlab-token", Status: CheckSkip, Detail: "no token configured (--gitlab-token, GITLAB_TOKEN, or `auth login`)"}
+	}
+
+	client, err := gitlab.NewClient(cfg.GitLabToken)
+	if err != nil {
+		return CheckResult{Name: "gitlab-token", Status: CheckFail, Detail: err.Error()}
+	}
+
+	user, _, err := client.Users.CurrentUser()
+	if err != nil {
+		return CheckResult{Name: "gitlab-token", Status: CheckFail, Detail: fmt.Sprintf("token rejected: %v", err)}
+	}
+
+	token, _, err := client.PersonalAccessTokens.GetSinglePersonalAccessToken()
+	if err != nil {
+		return CheckResult{Name: "gitlab-token", Status: CheckPass, Detail: fmt.Sprintf("authenticated as %s (scopes not verifiable: %v)", user.Username, err)}
+	}
+
+	missing := missingScopes(token.Scopes, []string{"api", "read_repository"})
+	if len(missing) > 0 {
+		return CheckResult{Name: "gitlab-token", Status: CheckFail, Detail: fmt.Sprintf("authenticated as %s but missing scope(s): %s", user.Username, strings.Join(missing, ", "))}
+	}
+
+	return CheckResult{Name: "gitlab-token", Status: CheckPass, Detail: fmt.Sprintf("authenticated as %s", user.Username)}
+}
+
+func missingScopes(have, want []string) []string {
+	haveSet := make(map[string]bool, len(have))
+	for _, scope := range have {
+		haveSet[scope] = true
+	}
+
+	var missing []string
+	for _, scope := range want {
+		if !haveSet[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+func checkSigningKey(cfg *Config) CheckResult {
+	if cfg.SigningKey == "" {
+		return CheckResult{Name: "signing-key", Status: CheckSkip, Detail: "no --signing-key configured"}
+	}
+
+	out, err := exec.Command("gpg", "--list-secret-keys", cfg.SigningKey).Output()
+	if err != nil {
+		return CheckResult{Name: "signing-key", Status: CheckFail, Detail: fmt.Sprintf("%s is not importable by gpg: %v", cfg.SigningKey, err)}
+	}
+	if len(strings.TrimSpace(string(out))) == 0 {
+		return CheckResult{Name: "signing-key", Status: CheckFail, Detail: cfg.SigningKey + " not found in gpg secret keyring"}
+	}
+
+	return CheckResult{Name: "signing-key", Status: CheckPass, Detail: cfg.SigningKey}
+}
+
+func printDoctorReport(results []CheckResult, asJSON bool) bool {
+	allPassed := true
+	for _, r := range results {
+		if r.Status == CheckFail {
+			allPassed = false
+		}
+	}
+
+	if asJSON {
+		out, err := json.MarshalIndent(results, "", "  ")
+		if err == nil {
+			fmt.Println(string(out))
+		}
+		return allPassed
+	}
+
+	for _, r := range results {
+		label := map[CheckStatus]string{CheckPass: "PASS", CheckFail: "FAIL", CheckSkip: "SKIP"}[r.Status]
+		fmt.Printf("[%s] %-16s %s\n", label, r.Name, r.Detail)
+	}
+
+	return allPassed
+}
+
+var doctorCmd = &cobra.Command{
+	Use:     "check",
+	Aliases: []string{"doctor"},
+	Short:   "Run preflight sanity checks on the environment",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := NewConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		results := runDoctorChecks(cfg)
+
+		if !printDoctorReport(results, getStringFlag(cmd, "output", "text") == "json") {
+			return fmt.Errorf("one or more preflight checks failed")
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().String("repo-name", stringFlagDefault("repo-name", "prismlinux"), "Repository name")
+	doctorCmd.Flags().String("arch", stringFlagDefault("arch", "x86_64"), "Target architecture")
+	doctorCmd.Flags().String("repo-arch-dir", "", "Architecture-specific repo directory (auto-determined)")
+	doctorCmd.Flags().String("api-dir", stringFlagDefault("api-dir", "api"), "API directory for metadata")
+	doctorCmd.Flags().Bool("testing", boolFlagDefault("testing", false), "Check the testing repository instead of stable")
+	doctorCmd.Flags().String("signing-key", "", "GPG key ID to verify is importable")
+
+	RootCmd.AddCommand(doctorCmd)
+}
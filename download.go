@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	downloadMaxAttempts = 3
+	downloadBaseBackoff = 500 * time.Millisecond
+	downloadTimeout     = 5 * time.Minute
+)
+
+// FileState is what we remember about a previously downloaded package so
+// later runs can skip re-hashing unchanged files and issue conditional GETs.
+type FileState struct {
+	SHA256       string `json:"sha256"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Size         int64  `json:"size"`
+}
+
+// RunSummary records what happened to a repository during the most recent
+// sync, keyed by target repo ("stable"/"testing") in DownloadState. The
+// dashboard generator uses it to annotate package status.
+type RunSummary struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Failed  []string `json:"failed,omitempty"`
+}
+
+// DownloadState is the on-disk cache of FileState, keyed by filename, stored
+// alongside the API directory.
+type DownloadState struct {
+	Files   map[string]FileState  `json:"files"`
+	LastRun map[string]RunSummary `json:"last_run,omitempty"`
+}
+
+func downloadStatePath(cfg *Config) string {
+	return filepath.Join(filepath.Dir(cfg.APIDir), "state.json")
+}
+
+func loadDownloadState(cfg *Config) (*DownloadState, error) {
+	data, err := os.ReadFile(downloadStatePath(cfg))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &DownloadState{Files: make(map[string]FileState)}, nil
+		}
+		return nil, fmt.Errorf("failed to read state.json: %w", err)
+	}
+
+	var state DownloadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state.json: %w", err)
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]FileState)
+	}
+	return &state, nil
+}
+
+func (s *DownloadState) save(cfg *Config) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state.json: %w", err)
+	}
+	if err := os.WriteFile(downloadStatePath(cfg), data, 0644); err != nil {
+		return fmt.Errorf("failed to write state.json: %w", err)
+	}
+	return nil
+}
+
+// downloadNewPackages fetches every not-yet-local package, plus any already-
+// local package whose remote ETag/Last-Modified has since moved on, using a
+// bounded worker pool with per-file retry/resume/checksum verification.
+// Failures are collected and reported together once all workers finish,
+// rather than silently skipped.
+func (pm *PackageManager) downloadNewPackages(remotePackages map[string]RemotePackage) error {
+	state, err := loadDownloadState(pm.config)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu              sync.Mutex
+		downloadedCount int
+		downloadErrors  []error
+		addedNames      []string
+		failedNames     []string
+	)
+
+	sem := semaphore.NewWeighted(int64(pm.config.MaxParallel))
+	g, ctx := errgroup.WithContext(context.Background())
+
+	for filename, pkg := range remotePackages {
+		filename, pkg := filename, pkg
+		localPath := filepath.Join(pm.config.RepoArchDir, filename)
+
+		if _, err := os.Stat(localPath); err == nil {
+			// Already have this filename locally. Normally that's enough (a
+			// versioned GitLab/mirror asset never changes under the same
+			// name), but fetchRemoteURLPackages's non-versioned URLs reuse
+			// the same filename forever, so check whether the remote's
+			// ETag/Last-Modified moved on before skipping it for good.
+			changed, etag, lastModified, err := remoteChanged(pkg.URL, state.Files[filename])
+			if err != nil {
+				pm.config.debugLog("Failed to check %s for remote changes, leaving local copy as-is: %v", filename, err)
+				continue
+			}
+			if !changed {
+				if etag != "" || lastModified != "" {
+					mu.Lock()
+					cached := state.Files[filename]
+					cached.ETag, cached.LastModified = etag, lastModified
+					state.Files[filename] = cached
+					mu.Unlock()
+				}
+				continue
+			}
+			pm.config.verboseLog("Remote content changed for %s, re-downloading", filename)
+		}
+
+		if err := sem.Acquire(ctx, 1); err != nil {
+			break
+		}
+
+		g.Go(func() error {
+			defer sem.Release(1)
+
+			fileState, err := pm.downloadPackageWithRetry(localPath, pkg)
+			if err != nil {
+				mu.Lock()
+				downloadErrors = append(downloadErrors, fmt.Errorf("%s: %w", filename, err))
+				failedNames = append(failedNames, filename)
+				mu.Unlock()
+				return nil
+			}
+
+			if err := pm.fetchAndVerifySignature(localPath, pkg); err != nil {
+				mu.Lock()
+				downloadErrors = append(downloadErrors, fmt.Errorf("%s: %w", filename, err))
+				failedNames = append(failedNames, filename)
+				mu.Unlock()
+				os.Remove(localPath)
+				os.Remove(localPath + ".sig")
+				return nil
+			}
+
+			mu.Lock()
+			state.Files[filename] = *fileState
+			addedNames = append(addedNames, filename)
+			downloadedCount++
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	_ = g.Wait() // per-file errors are collected above; this only guards sem.Acquire
+
+	if state.LastRun == nil {
+		state.LastRun = make(map[string]RunSummary)
+	}
+	summary := state.LastRun[pm.config.runKey()]
+	summary.Added = addedNames
+	summary.Failed = failedNames
+	state.LastRun[pm.config.runKey()] = summary
+
+	if err := state.save(pm.config); err != nil {
+		pm.config.debugLog("Failed to persist download state: %v", err)
+	}
+
+	if downloadedCount > 0 {
+		pm.config.infoLog("Downloaded %d new packages", downloadedCount)
+	}
+
+	if len(downloadErrors) > 0 {
+		msgs := make([]string, len(downloadErrors))
+		for i, e := range downloadErrors {
+			msgs[i] = e.Error()
+		}
+		return fmt.Errorf("%d package(s) failed to download:\n  %s", len(downloadErrors), strings.Join(msgs, "\n  "))
+	}
+
+	return nil
+}
+
+// downloadPackageWithRetry downloads a single package with exponential
+// backoff retry, Range-based resume of a partial .part file, and SHA256
+// verification against a sidecar .sha256 when the remote publishes one.
+func (pm *PackageManager) downloadPackageWithRetry(localPath string, pkg RemotePackage) (*FileState, error) {
+	partPath := localPath + ".part"
+
+	var lastErr error
+	for attempt := 1; attempt <= downloadMaxAttempts; attempt++ {
+		if attempt > 1 {
+			backoff := downloadBaseBackoff * time.Duration(1<<uint(attempt-2))
+			jitter := time.Duration(rand.Int63n(int64(backoff) / 2))
+			pm.config.debugLog("Retrying download of %s (attempt %d/%d) after %v", pkg.Filename, attempt, downloadMaxAttempts, backoff+jitter)
+			time.Sleep(backoff + jitter)
+		}
+
+		etag, lastModified, err := downloadWithResume(partPath, pkg.URL)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		sum, err := sha256File(partPath)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		expected := pkg.ExpectedSHA256
+		if expected == "" {
+			expected, _ = pm.fetchSidecarChecksum(pkg.URL)
+		}
+		if expected != "" && !strings.EqualFold(expected, sum) {
+			os.Remove(partPath)
+			lastErr = fmt.Errorf("checksum mismatch: expected %s, got %s", expected, sum)
+			continue
+		}
+
+		if err := os.Rename(partPath, localPath); err != nil {
+			lastErr = fmt.Errorf("failed to finalize %s: %w", localPath, err)
+			continue
+		}
+
+		info, err := os.Stat(localPath)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		return &FileState{SHA256: sum, Size: info.Size(), ETag: etag, LastModified: lastModified}, nil
+	}
+
+	os.Remove(partPath)
+	return nil, lastErr
+}
+
+// downloadWithResume downloads url into partPath, resuming from the current
+// size of partPath (if any) via an HTTP Range request. It returns the
+// response's ETag/Last-Modified so the caller can cache them for future
+// conditional requests.
+func downloadWithResume(partPath, url string) (etag, lastModified string, err error) {
+	var resumeFrom int64
+	if info, err := os.Stat(partPath); err == nil {
+		resumeFrom = info.Size()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build request for %s: %w", url, err)
+	}
+	if resumeFrom > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	var out *os.File
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		out, err = os.OpenFile(partPath, os.O_APPEND|os.O_WRONLY, 0644)
+	case http.StatusOK:
+		out, err = os.Create(partPath)
+	default:
+		return "", "", fmt.Errorf("bad status: %s", resp.Status)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open %s: %w", partPath, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", partPath, err)
+	}
+
+	return resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), nil
+}
+
+// remoteChanged issues a conditional GET-equivalent HEAD for url, comparing
+// the response's ETag/Last-Modified against cached. It reports whether the
+// remote content looks different from what's cached (true when cached is
+// empty, since there's nothing to compare against yet), plus the metadata
+// observed so the caller can refresh its cache either way. This is how a
+// non-versioned remote URL (fetchRemoteURLPackages's same-filename-forever
+// case) gets noticed and re-downloaded instead of being skipped forever just
+// because a file of that name already exists locally.
+func remoteChanged(url string, cached FileState) (changed bool, etag, lastModified string, err error) {
+	resp, err := http.Head(url)
+	if err != nil {
+		return false, "", "", fmt.Errorf("failed to HEAD %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, "", "", fmt.Errorf("bad status for HEAD %s: %s", url, resp.Status)
+	}
+
+	etag = resp.Header.Get("ETag")
+	lastModified = resp.Header.Get("Last-Modified")
+
+	if cached.ETag == "" && cached.LastModified == "" {
+		return true, etag, lastModified, nil
+	}
+	if etag != "" {
+		return etag != cached.ETag, etag, lastModified, nil
+	}
+	if lastModified != "" {
+		return lastModified != cached.LastModified, etag, lastModified, nil
+	}
+
+	return true, etag, lastModified, nil
+}
+
+// fetchSidecarChecksum fetches the <url>.sha256 sidecar, if one exists, and
+// returns the expected hex-encoded SHA256 it contains.
+func (pm *PackageManager) fetchSidecarChecksum(url string) (string, error) {
+	resp, err := http.Get(url + ".sha256")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("no sidecar checksum: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(strings.TrimSpace(string(data)))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty sidecar checksum at %s.sha256", url)
+	}
+
+	return fields[0], nil
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// downloadFile is the plain, non-resumable helper used for small ancillary
+// fetches (detached signatures, checksum sidecars) where retry/resume would
+// be overkill.
+func (pm *PackageManager) downloadFile(filepath string, url string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad status: %s", resp.Status)
+	}
+
+	out, err := os.Create(filepath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filepath, err)
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filepath, err)
+	}
+
+	return nil
+}
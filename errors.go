@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// usageMode controls how printError renders errors: "prod" (the default)
+// shows a short user-facing message, "dev" shows the full wrapped chain.
+// Override at build time with -ldflags "-X main.usageMode=dev", or at
+// runtime with --debug.
+var usageMode = "prod"
+
+// ErrorCode categorizes a RepoError for JSON output and for deciding which
+// checks a caller (e.g. CI) should retry versus treat as a hard failure.
+type ErrorCode string
+
+const (
+	ErrAuth      ErrorCode = "auth"
+	ErrNetwork   ErrorCode = "network"
+	ErrRepoState ErrorCode = "repo_state"
+	ErrUsage     ErrorCode = "usage"
+)
+
+// RepoError is this CLI's structured error type: a stable code plus a
+// short user-facing message, wrapping whatever underlying error (HTTP
+// failure, os.PathError, ...) caused it.
+type RepoError struct {
+	Code    ErrorCode
+	Message string
+	Err     error
+}
+
+func (e *RepoError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *RepoError) Unwrap() error {
+	return e.Err
+}
+
+func newRepoError(code ErrorCode, message string, err error) *RepoError {
+	return &RepoError{Code: code, Message: message, Err: err}
+}
+
+func NewAuthError(message string, err error) *RepoError { return newRepoError(ErrAuth, message, err) }
+func NewNetworkError(message string, err error) *RepoError {
+	return newRepoError(ErrNetwork, message, err)
+}
+func NewRepoStateError(message string, err error) *RepoError {
+	return newRepoError(ErrRepoState, message, err)
+}
+func NewUsageError(message string, err error) *RepoError { return newRepoError(ErrUsage, message, err) }
+
+// errorEnvelope is the stable JSON shape for `--output json` error
+// reporting: {"error": {"code": ..., "message": ..., "details": ...}}.
+type errorEnvelope struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Details string `json:"details,omitempty"`
+	} `json:"error"`
+}
+
+// printError is main's single error-reporting path. In dev mode (--debug,
+// or usageMode built as "dev") it prints the full wrapped chain via %+v;
+// in prod mode it prints just the top-level message and hides the chain.
+// In JSON mode it emits a single errorEnvelope object to stdout instead.
+func printError(err error, debug bool, jsonOutput bool) {
+	var repoErr *RepoError
+	code := string(ErrRepoState)
+	message := err.Error()
+	if errors.As(err, &repoErr) {
+		code = string(repoErr.Code)
+		message = repoErr.Message
+	}
+
+	if jsonOutput {
+		var envelope errorEnvelope
+		envelope.Error.Code = code
+		envelope.Error.Message = message
+		if debug || usageMode == "dev" {
+			envelope.Error.Details = fmt.Sprintf("%+v", err)
+		}
+		if out, marshalErr := json.MarshalIndent(envelope, "", "  "); marshalErr == nil {
+			fmt.Fprintln(os.Stdout, string(out))
+		}
+		return
+	}
+
+	if debug || usageMode == "dev" {
+		fmt.Printf("Error: %+v\n", err)
+		return
+	}
+
+	fmt.Println("Error:", message)
+}
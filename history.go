@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gitlab.com/prismlinux/repository/store"
+)
+
+// historyStorePath is where the per-package version history SQLite database
+// lives, alongside the API directory and state.json.
+func historyStorePath(cfg *Config) string {
+	return filepath.Join(filepath.Dir(cfg.APIDir), "history.db")
+}
+
+// recordPackageHistory upserts every package in packageList into the store
+// and fills in PreviousVersion/UpdatedAt from what was already recorded.
+func (pm *PackageManager) recordPackageHistory(packageList []PackageInfo, remotePackages map[string]RemotePackage) {
+	if pm.store == nil {
+		return
+	}
+
+	now := time.Now()
+	dlState, _ := loadDownloadState(pm.config)
+
+	for i := range packageList {
+		pkg := &packageList[i]
+
+		prev, err := pm.store.PreviousVersion(pm.config.getTargetRepo(), pm.config.Architecture, pkg.Name, pkg.Version)
+		if err != nil {
+			pm.config.debugLog("Failed to look up previous version for %s: %v", pkg.Name, err)
+		} else {
+			pkg.PreviousVersion = prev
+		}
+
+		sha256 := ""
+		if dlState != nil {
+			sha256 = dlState.Files[pkg.Filename].SHA256
+		}
+
+		remote := remotePackages[pkg.Filename]
+		entry := store.Entry{
+			Repo:       pm.config.getTargetRepo(),
+			Arch:       pm.config.Architecture,
+			Name:       pkg.Name,
+			Version:    pkg.Version,
+			Filename:   pkg.Filename,
+			SHA256:     sha256,
+			SourceURL:  remote.URL,
+			ReleaseTag: remote.ReleaseTag,
+		}
+
+		if err := pm.store.Record(entry, now); err != nil {
+			pm.config.debugLog("Failed to record history for %s: %v", pkg.Name, err)
+			continue
+		}
+
+		pkg.UpdatedAt = now.Format("2006-01-02 15:04:05")
+	}
+}
+
+// selectPromotedRelease returns the newest release that has spent at least
+// PromoteAfterDays in testing, or nil if none qualify yet.
+func (pm *PackageManager) selectPromotedRelease(allReleases []*gitlab.Release) (*gitlab.Release, error) {
+	threshold := time.Duration(pm.config.PromoteAfterDays) * 24 * time.Hour
+
+	for _, release := range allReleases {
+		seenAt, ok, err := pm.store.FirstSeenInRepo("testing", pm.config.Architecture, release.TagName)
+		if err != nil {
+			return nil, err
+		}
+		if ok && time.Since(seenAt) >= threshold {
+			return release, nil
+		}
+	}
+
+	return nil, nil
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history <package>",
+	Short: "Print the recorded version timeline for a package",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := NewConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		pkgStore, err := store.Open(historyStorePath(cfg))
+		if err != nil {
+			return fmt.Errorf("failed to open package history store: %w", err)
+		}
+		defer pkgStore.Close()
+
+		entries, err := pkgStore.History(cfg.getTargetRepo(), cfg.Architecture, args[0])
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			fmt.Printf("No recorded history for %s in %s (%s)\n", args[0], cfg.getTargetRepo(), cfg.Architecture)
+			return nil
+		}
+
+		fmt.Printf("History for %s in %s (%s):\n", args[0], cfg.getTargetRepo(), cfg.Architecture)
+		for _, e := range entries {
+			fmt.Printf("  %s  first seen %s, last seen %s, tag %s\n",
+				e.Version, e.FirstSeen.Format("2006-01-02"), e.LastSeen.Format("2006-01-02"), e.ReleaseTag)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	historyCmd.Flags().String("repo-name", stringFlagDefault("repo-name", "prismlinux"), "Repository name")
+	historyCmd.Flags().String("arch", stringFlagDefault("arch", "x86_64"), "Target architecture")
+	historyCmd.Flags().String("api-dir", stringFlagDefault("api-dir", "api"), "API directory for metadata")
+	historyCmd.Flags().Bool("testing", boolFlagDefault("testing", false), "Look up history in the testing repository instead of stable")
+
+	RootCmd.AddCommand(historyCmd)
+}
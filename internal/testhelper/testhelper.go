@@ -0,0 +1,189 @@
+// Package testhelper supports the end-to-end suites in the root package's
+// *_test.go files: it builds the CLI as a coverage-instrumented test binary
+// once per run, execs it against a throwaway repo tree, and strips ANSI so
+// stdout/stderr assertions don't depend on whether the CLI thought it had a
+// TTY.
+package testhelper
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	buildOnce sync.Once
+	buildErr  error
+	binPath   string
+)
+
+// BuildCLI compiles the module into testdata/repository.test via
+// `go test -c`, so the binary carries both the production code and
+// TestHelperProcess (see main_test.go), and can be exec'd repeatedly as the
+// CLI itself. The build runs at most once per test binary invocation; every
+// caller across every *_test.go file shares the same compiled binary.
+func BuildCLI(tb testing.TB) string {
+	tb.Helper()
+
+	buildOnce.Do(func() {
+		root, err := moduleRoot()
+		if err != nil {
+			buildErr = err
+			return
+		}
+
+		outPath := filepath.Join(root, "testdata", "repository.test")
+		if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+			buildErr = fmt.Errorf("failed to create %s: %w", filepath.Dir(outPath), err)
+			return
+		}
+
+		covermode := os.Getenv("COVERMODE")
+		if covermode == "" {
+			covermode = "atomic"
+		}
+
+		cmd := exec.Command("go", "test", "-c",
+			"-covermode="+covermode,
+			"-coverpkg=./...",
+			"-o", outPath,
+			".",
+		)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			buildErr = fmt.Errorf("failed to build CLI test binary: %w\n%s", err, out)
+			return
+		}
+
+		binPath = outPath
+	})
+
+	if buildErr != nil {
+		tb.Fatalf("BuildCLI: %v", buildErr)
+	}
+	return binPath
+}
+
+// moduleRoot resolves the repository root from this file's own location,
+// rather than the test's working directory, so BuildCLI works regardless of
+// which package invokes it.
+func moduleRoot() (string, error) {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return "", fmt.Errorf("failed to resolve testhelper.go's own path")
+	}
+	// internal/testhelper/testhelper.go -> repository root is two levels up.
+	return filepath.Abs(filepath.Join(filepath.Dir(file), "..", ".."))
+}
+
+// Result is one CLI invocation's outcome, with ANSI escapes already
+// stripped from both streams.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
+
+// StripANSI removes terminal color/cursor escape sequences.
+func StripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// Run execs the binary built by BuildCLI against args, with dir as its
+// working directory. extraEnv entries (e.g. "HOME=...") override the
+// ambient environment; PRISMREPO_HELPER_PROCESS and PRISMREPO_NO_UPDATE_CHECK
+// are always set so the binary runs as the CLI instead of a test suite and
+// never reaches out for the background update check.
+func Run(tb testing.TB, binPath, dir string, extraEnv []string, args ...string) Result {
+	tb.Helper()
+
+	helperArgs := append([]string{"-test.run=TestHelperProcess", "--"}, args...)
+	cmd := exec.Command(binPath, helperArgs...)
+	cmd.Dir = dir
+	cmd.Env = buildEnv(extraEnv)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	exitCode := 0
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			tb.Fatalf("failed to run CLI binary: %v", err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	return Result{
+		Stdout:   StripANSI(stdout.String()),
+		Stderr:   StripANSI(stderr.String()),
+		ExitCode: exitCode,
+	}
+}
+
+// buildEnv overlays extra onto the ambient environment, keying by variable
+// name so an override always wins regardless of libc's getenv scan order.
+func buildEnv(extra []string) []string {
+	overrides := map[string]string{
+		"PRISMREPO_HELPER_PROCESS":  "1",
+		"PRISMREPO_NO_UPDATE_CHECK": "1",
+	}
+	for _, kv := range extra {
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			overrides[kv[:i]] = kv[i+1:]
+		}
+	}
+
+	env := os.Environ()
+	merged := make([]string, 0, len(env)+len(overrides))
+	for _, kv := range env {
+		key := kv
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			key = kv[:i]
+		}
+		if _, overridden := overrides[key]; overridden {
+			continue
+		}
+		merged = append(merged, kv)
+	}
+	for key, value := range overrides {
+		merged = append(merged, key+"="+value)
+	}
+	return merged
+}
+
+// NewMockGitLabServer returns an httptest.Server answering
+// /api/v4/projects/*/releases with a single canned release. It's a starting
+// point for suites that exercise check-updates/update against a fake
+// GitLab, without hitting the network.
+func NewMockGitLabServer(tb testing.TB) *httptest.Server {
+	tb.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/releases") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"tag_name":"v1.0.0","name":"v1.0.0","released_at":"2026-01-01T00:00:00Z","assets":{"links":[]}}]`)
+	})
+
+	server := httptest.NewServer(mux)
+	tb.Cleanup(server.Close)
+	return server
+}
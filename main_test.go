@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain lets `go test -c` (see internal/testhelper.BuildCLI) produce a
+// single binary that doubles as both this package's test suite and the
+// repository CLI: TestHelperProcess below runs main() instead of a real
+// test when exec'd with PRISMREPO_HELPER_PROCESS=1, so every e2e suite in
+// this package spawns that one compiled binary rather than rebuilding per
+// test case.
+func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+}
+
+// TestHelperProcess is not a real test. It's the entry point
+// testhelper.Run execs via `-test.run=TestHelperProcess -- <cli args>`: it
+// runs the production main() against os.Args trimmed to whatever follows
+// "--", then exits immediately. The explicit os.Exit (rather than letting
+// TestHelperProcess return normally) matters: otherwise `go test` appends
+// its own "PASS\ncoverage: ...\n" summary to stdout after main() returns,
+// corrupting anything the CLI itself printed (e.g. `status --output json`).
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("PRISMREPO_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for i, a := range args {
+		if a == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+
+	RootCmd.SetArgs(args)
+	main()
+	os.Exit(0)
+}
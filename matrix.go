@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// resolveArchMatrix decides which architectures a run should cover: the
+// --arches flag wins if set, then packages_config.yaml's `architectures`
+// list, falling back to the single --arch value for back-compat with
+// single-arch setups.
+func resolveArchMatrix(cmd *cobra.Command, configuredArches []string) []string {
+	if archesFlag, _ := cmd.Flags().GetString("arches"); strings.TrimSpace(archesFlag) != "" {
+		var arches []string
+		for _, arch := range strings.Split(archesFlag, ",") {
+			if arch = strings.TrimSpace(arch); arch != "" {
+				arches = append(arches, arch)
+			}
+		}
+		return arches
+	}
+
+	if len(configuredArches) > 0 {
+		return configuredArches
+	}
+
+	return []string{getStringFlag(cmd, "arch", "x86_64")}
+}
+
+// forEachArch runs fn once per architecture in the resolved matrix, each
+// time against a Config retargeted at that architecture.
+func forEachArch(cmd *cobra.Command, configuredArches []string, cfg *Config, fn func(*Config) error) error {
+	arches := resolveArchMatrix(cmd, configuredArches)
+
+	for _, arch := range arches {
+		archCfg := cfg.forArchitecture(arch)
+		if len(arches) > 1 {
+			fmt.Printf("=== Architecture: %s ===\n", arch)
+		}
+		if err := fn(archCfg); err != nil {
+			return fmt.Errorf("arch %s: %w", arch, err)
+		}
+	}
+
+	return nil
+}
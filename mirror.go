@@ -0,0 +1,270 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PacmanMirror is an upstream pacman repository to mirror, with an optional
+// filter restricting which packages get pulled in.
+type PacmanMirror struct {
+	URL           string   `yaml:"url"`
+	Repos         string   `yaml:"repos"` // semicolon separated, e.g. "core;extra;multilib"
+	Architectures []string `yaml:"architectures,omitempty"`
+	// Filter matches package names via glob (default) or, prefixed with
+	// "re:", a regular expression. Empty means mirror everything.
+	Filter string `yaml:"filter,omitempty"`
+	// WithDeps additionally pulls in the transitive dependency closure of
+	// whatever Filter matched, mirroring aptly's FilterWithDeps.
+	WithDeps bool `yaml:"with_deps,omitempty"`
+	Enabled  bool `yaml:"enabled"`
+}
+
+// mirrorDesc is one package's entry parsed out of a repo's desc database.
+type mirrorDesc struct {
+	Name     string
+	Version  string
+	Filename string
+	CSize    string
+	MD5Sum   string
+	SHA256   string
+	Depends  []string
+}
+
+// fetchPacmanMirrorPackages downloads and parses each enabled mirror's
+// <repo>.db.tar.gz, filters by package name, and returns RemotePackages
+// pointing at the upstream package files.
+func (pm *PackageManager) fetchPacmanMirrorPackages(mirrors []PacmanMirror) ([]RemotePackage, error) {
+	var packages []RemotePackage
+
+	for _, mirror := range mirrors {
+		if !mirror.Enabled {
+			continue
+		}
+
+		architectures := mirror.Architectures
+		if len(architectures) > 0 && !containsString(architectures, pm.config.Architecture) {
+			pm.config.verboseLog("Skipping mirror %s: not configured for arch %s", mirror.URL, pm.config.Architecture)
+			continue
+		}
+
+		for _, repo := range strings.Split(mirror.Repos, ";") {
+			repo = strings.TrimSpace(repo)
+			if repo == "" {
+				continue
+			}
+
+			baseURL := strings.NewReplacer("$repo", repo, "$arch", pm.config.Architecture).Replace(mirror.URL)
+			dbURL := baseURL + "/" + repo + ".db.tar.gz"
+
+			descs, err := fetchMirrorDescs(dbURL)
+			if err != nil {
+				pm.config.debugLog("Failed to fetch mirror database %s: %v", dbURL, err)
+				continue
+			}
+
+			matched, err := filterMirrorDescs(descs, mirror.Filter, mirror.WithDeps)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter for mirror %s: %w", mirror.URL, err)
+			}
+
+			for _, desc := range matched {
+				if desc.Filename == "" {
+					continue
+				}
+				packages = append(packages, RemotePackage{
+					Filename:       desc.Filename,
+					URL:            baseURL + "/" + desc.Filename,
+					Repository:     pm.config.getTargetRepo(),
+					Source:         remoteURLHost(mirror.URL),
+					ExpectedSHA256: desc.SHA256,
+				})
+			}
+
+			pm.config.verboseLog("Mirror %s/%s: %d of %d packages matched filter", mirror.URL, repo, len(matched), len(descs))
+		}
+	}
+
+	pm.config.infoLog("Found %d packages from pacman mirrors", len(packages))
+	return packages, nil
+}
+
+// fetchMirrorDescs downloads a repo database (gzip+tar of "desc" files, one
+// per package) and parses every entry.
+func fetchMirrorDescs(dbURL string) ([]mirrorDesc, error) {
+	resp, err := http.Get(dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", dbURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad status for %s: %s", dbURL, resp.Status)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	var descs []mirrorDesc
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar entry: %w", err)
+		}
+		if !strings.HasSuffix(header.Name, "/desc") {
+			continue
+		}
+
+		desc, err := parseMirrorDesc(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", header.Name, err)
+		}
+		descs = append(descs, desc)
+	}
+
+	return descs, nil
+}
+
+// parseMirrorDesc parses pacman's "desc" key/value block format:
+//
+//	%FILENAME%
+//	name-ver-rel-arch.pkg.tar.zst
+//
+//	%DEPENDS%
+//	dep1
+//	dep2>=1.0
+func parseMirrorDesc(r io.Reader) (mirrorDesc, error) {
+	var desc mirrorDesc
+	var currentKey string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "%") && strings.HasSuffix(line, "%") {
+			currentKey = strings.Trim(line, "%")
+			continue
+		}
+		if line == "" {
+			currentKey = ""
+			continue
+		}
+
+		switch currentKey {
+		case "NAME":
+			desc.Name = line
+		case "VERSION":
+			desc.Version = line
+		case "FILENAME":
+			desc.Filename = line
+		case "CSIZE":
+			desc.CSize = line
+		case "MD5SUM":
+			desc.MD5Sum = line
+		case "SHA256SUM":
+			desc.SHA256 = line
+		case "DEPENDS":
+			desc.Depends = append(desc.Depends, line)
+		}
+	}
+
+	return desc, scanner.Err()
+}
+
+// filterMirrorDescs narrows descs down to those matching filter (glob by
+// default, regex when prefixed "re:"), optionally walking DEPENDS to also
+// include the transitive dependency closure.
+func filterMirrorDescs(descs []mirrorDesc, filter string, withDeps bool) ([]mirrorDesc, error) {
+	if filter == "" {
+		return descs, nil
+	}
+
+	matches, err := mirrorNameMatcher(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]mirrorDesc, len(descs))
+	for _, desc := range descs {
+		byName[desc.Name] = desc
+	}
+
+	selected := make(map[string]bool)
+	var queue []string
+	for _, desc := range descs {
+		if matches(desc.Name) {
+			queue = append(queue, desc.Name)
+		}
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if selected[name] {
+			continue
+		}
+		selected[name] = true
+
+		if !withDeps {
+			continue
+		}
+		desc, ok := byName[name]
+		if !ok {
+			continue
+		}
+		for _, dep := range desc.Depends {
+			depName := stripVersionConstraint(dep)
+			if _, ok := byName[depName]; ok && !selected[depName] {
+				queue = append(queue, depName)
+			}
+		}
+	}
+
+	var result []mirrorDesc
+	for name := range selected {
+		if desc, ok := byName[name]; ok {
+			result = append(result, desc)
+		}
+	}
+	return result, nil
+}
+
+func mirrorNameMatcher(filter string) (func(string) bool, error) {
+	if rest, ok := strings.CutPrefix(filter, "re:"); ok {
+		re, err := regexp.Compile(rest)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+
+	return func(name string) bool {
+		matched, _ := filepath.Match(filter, name)
+		return matched
+	}, nil
+}
+
+// stripVersionConstraint turns a DEPENDS entry like "glibc>=2.38" into the
+// bare package name "glibc".
+func stripVersionConstraint(dep string) string {
+	for _, sep := range []string{">=", "<=", "=", ">", "<"} {
+		if idx := strings.Index(dep, sep); idx != -1 {
+			return dep[:idx]
+		}
+	}
+	return dep
+}
@@ -4,37 +4,57 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gitlab.com/prismlinux/repository/store"
 	"gopkg.in/yaml.v3"
 )
 
 // Models
 type PackageInfo struct {
-	Name         string `json:"name"`
-	Version      string `json:"version"`
-	Description  string `json:"description"`
-	Architecture string `json:"architecture"`
-	Filename     string `json:"filename"`
-	Size         string `json:"size"`
-	Modified     string `json:"modified"`
-	Depends      string `json:"depends"`
-	Groups       string `json:"groups"`
-	Repository   string `json:"repository"`
+	Name            string `json:"name"`
+	Version         string `json:"version"`
+	Description     string `json:"description"`
+	Architecture    string `json:"architecture"`
+	Filename        string `json:"filename"`
+	Size            string `json:"size"`
+	Modified        string `json:"modified"`
+	Depends         string `json:"depends"`
+	Groups          string `json:"groups"`
+	Repository      string `json:"repository"`
+	Signed          bool   `json:"signed"`
+	Source          string `json:"source,omitempty"`
+	SourceURL       string `json:"source_url,omitempty"`
+	PreviousVersion string `json:"previous_version,omitempty"`
+	UpdatedAt       string `json:"updated_at,omitempty"`
 }
 
 type RemotePackage struct {
 	Filename   string
 	URL        string
 	Repository string
+	// SigURL is the detached-signature asset/URL paired with this package,
+	// if one was found next to it on the remote. Empty when unsigned.
+	SigURL string
+	// Source identifies where the package came from: the GitLab project
+	// name, or the remote URL's host.
+	Source string
+	// ReleaseTag is the GitLab release tag this package was published
+	// under, when known.
+	ReleaseTag string
+	// ExpectedSHA256 is the checksum to verify the download against, when
+	// known up front (e.g. parsed from a pacman mirror's SHA256SUM entry).
+	// Takes priority over fetchSidecarChecksum's HTTP sidecar, which real
+	// pacman mirrors don't serve.
+	ExpectedSHA256 string
 }
 
 type GitLabProject struct {
@@ -42,6 +62,12 @@ type GitLabProject struct {
 	Name       string `yaml:"name"`
 	Repository string `yaml:"repository"`
 	Enabled    bool   `yaml:"enabled"`
+	// Arches restricts which architectures this project publishes to.
+	// Empty means publish to every architecture in the matrix.
+	Arches []string `yaml:"arches,omitempty"`
+	// PinnedTag is the release tag `check-updates` last bumped this project
+	// to. Empty means no release has been pinned yet.
+	PinnedTag string `yaml:"pinned_tag,omitempty"`
 }
 
 type RemoteURL struct {
@@ -53,19 +79,80 @@ type RemoteURL struct {
 type PackagesConfig struct {
 	GitLabProjects []GitLabProject `yaml:"gitlab_projects"`
 	RemoteURLs     []RemoteURL     `yaml:"remote_urls"`
+	// Architectures is the default arch matrix for a run; overridden by
+	// the --arches flag. A single entry (e.g. ["x86_64"]) reproduces the
+	// historical single-arch behavior.
+	Architectures []string `yaml:"architectures,omitempty"`
+	// PacmanMirrors are upstream pacman repositories to mirror, filtered
+	// by package name.
+	PacmanMirrors []PacmanMirror `yaml:"pacman_mirrors,omitempty"`
 }
 
 // Config
 type Config struct {
-	RepoName    string
-	RepoArchDir string
-	APIDir      string
-	GitLabToken string
-	TestingMode bool
-	Debug       bool
-	Verbose     bool
-	dbBaseName  string // Internal: without sufix
-	targetRepo  string // Internal: "stable" or "testing"
+	RepoName          string
+	RepoArchDir       string
+	APIDir            string
+	GitLabToken       string
+	TestingMode       bool
+	Debug             bool
+	Verbose           bool
+	SigningKey        string
+	RequireSignatures bool
+	MaxParallel       int
+	Dashboard         bool
+	TemplateDir       string
+	PromoteAfterDays  int
+	Architecture      string // Internal: arch this Config targets, set by NewConfig/forArchitecture
+	dbBaseName        string // Internal: without sufix
+	targetRepo        string // Internal: "stable" or "testing"
+	baseRepoName      string // Internal: repo-name flag, used to rebuild per-arch dirs
+	explicitArchDir   string // Internal: raw --repo-arch-dir flag, empty unless user set it
+}
+
+// forArchitecture returns a copy of cfg retargeted at a different
+// architecture, recomputing RepoArchDir the same way NewConfig would unless
+// the user pinned an explicit --repo-arch-dir.
+func (cfg *Config) forArchitecture(arch string) *Config {
+	archCfg := *cfg
+	archCfg.Architecture = arch
+
+	if archCfg.explicitArchDir == "" {
+		if archCfg.TestingMode {
+			archCfg.RepoArchDir = filepath.Join("testing", arch)
+		} else {
+			archCfg.RepoArchDir = arch
+		}
+	}
+
+	return &archCfg
+}
+
+// forTargetAndArch returns a copy of cfg retargeted at a different (target
+// repo, architecture) pair, recomputing dbBaseName/RepoName/RepoArchDir the
+// same way NewConfig would. Used by the status report, which looks at both
+// "stable" and "testing" regardless of which one --testing selected.
+func (cfg *Config) forTargetAndArch(target, arch string) *Config {
+	targetCfg := *cfg
+	targetCfg.Architecture = arch
+	targetCfg.targetRepo = target
+
+	if target == "testing" {
+		targetCfg.dbBaseName = cfg.baseRepoName + "-testing"
+	} else {
+		targetCfg.dbBaseName = cfg.baseRepoName
+	}
+	targetCfg.RepoName = targetCfg.dbBaseName
+
+	if targetCfg.explicitArchDir == "" {
+		if target == "testing" {
+			targetCfg.RepoArchDir = filepath.Join("testing", arch)
+		} else {
+			targetCfg.RepoArchDir = arch
+		}
+	}
+
+	return &targetCfg
 }
 
 func (cfg *Config) debugLog(format string, args ...interface{}) {
@@ -96,10 +183,23 @@ func (cfg *Config) getTargetRepo() string {
 	return cfg.targetRepo
 }
 
+// apiFileName is the per-arch JSON metadata filename for this Config's
+// target repo, e.g. "stable-x86_64.json".
+func (cfg *Config) apiFileName() string {
+	return fmt.Sprintf("%s-%s.json", cfg.targetRepo, cfg.Architecture)
+}
+
+// runKey identifies this (target repo, architecture) pair in DownloadState's
+// LastRun map.
+func (cfg *Config) runKey() string {
+	return cfg.targetRepo + "-" + cfg.Architecture
+}
+
 // Package Manager
 type PackageManager struct {
 	config       *Config
 	gitlabClient *gitlab.Client
+	store        *store.Store
 }
 
 func NewPackageManager(cfg *Config) (*PackageManager, error) {
@@ -116,6 +216,13 @@ func NewPackageManager(cfg *Config) (*PackageManager, error) {
 		cfg.debugLog("No GitLab token provided - will only process remote URLs")
 	}
 
+	pkgStore, err := store.Open(historyStorePath(cfg))
+	if err != nil {
+		cfg.debugLog("Package history store unavailable: %v", err)
+	} else {
+		pm.store = pkgStore
+	}
+
 	return pm, nil
 }
 
@@ -176,6 +283,10 @@ func (fm *FileManager) removeRepositoryDatabase() error {
 		fm.config.getDBName(),
 		fm.config.dbBaseName + ".files",
 		fm.config.getFilesName(),
+		fm.config.getDBName() + ".sig",
+		fm.config.getFilesName() + ".sig",
+		fm.config.dbBaseName + ".db.sig",
+		fm.config.dbBaseName + ".files.sig",
 	}
 
 	for _, dbFile := range dbFiles {
@@ -203,7 +314,7 @@ func (fm *FileManager) createEmptyPackagesJSON() error {
 		return fmt.Errorf("failed to marshal empty JSON: %w", err)
 	}
 
-	apiFileName := fmt.Sprintf("%s.json", fm.config.getTargetRepo())
+	apiFileName := fm.config.apiFileName()
 	apiFilePath := filepath.Join(fm.config.APIDir, apiFileName)
 
 	err = os.WriteFile(apiFilePath, jsonData, 0644)
@@ -216,20 +327,79 @@ func (fm *FileManager) createEmptyPackagesJSON() error {
 }
 
 // Config initialization
+//
+// Flag resolution follows the precedence described for the `config`
+// subsystem: an explicitly-passed CLI flag wins, then a PRISMREPO_* env
+// var, then the loaded settings.Settings (user config overlaid by a
+// project-local .prismrepo.yml), then the flag's own registered default,
+// then defaultValue as a last-resort fallback.
 func getStringFlag(cmd *cobra.Command, name, defaultValue string) string {
+	if cmd.Flags().Changed(name) {
+		value, _ := cmd.Flags().GetString(name)
+		return value
+	}
+
+	if envValue, ok := os.LookupEnv(envVarName(name)); ok && envValue != "" {
+		return envValue
+	}
+
+	if cfg := settingsFromContext(cmd.Context()); cfg != nil {
+		if value, ok := cfg.Get(name); ok {
+			return value
+		}
+	}
+
 	if value, _ := cmd.Flags().GetString(name); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
+// getBoolFlag mirrors getStringFlag's precedence for the boolean flags
+// (testing, debug, verbose) shared across subcommands.
+func getBoolFlag(cmd *cobra.Command, name string, defaultValue bool) bool {
+	if cmd.Flags().Changed(name) {
+		value, _ := cmd.Flags().GetBool(name)
+		return value
+	}
+
+	if envValue, ok := os.LookupEnv(envVarName(name)); ok && envValue != "" {
+		if parsed, err := strconv.ParseBool(envValue); err == nil {
+			return parsed
+		}
+	}
+
+	if cfg := settingsFromContext(cmd.Context()); cfg != nil {
+		if value, ok := cfg.Get(name); ok {
+			if parsed, err := strconv.ParseBool(value); err == nil {
+				return parsed
+			}
+		}
+	}
+
+	value, _ := cmd.Flags().GetBool(name)
+	if value {
+		return true
+	}
+	return defaultValue
+}
+
+// envVarName maps a flag name like "repo-name" to its override env var,
+// PRISMREPO_REPO_NAME.
+func envVarName(flagName string) string {
+	return "PRISMREPO_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
 func NewConfig(cmd *cobra.Command) (*Config, error) {
 	cfg := &Config{}
 
 	baseRepoName := getStringFlag(cmd, "repo-name", "prismlinux")
-	cfg.TestingMode, _ = cmd.Flags().GetBool("testing")
+	cfg.baseRepoName = baseRepoName
+	cfg.TestingMode = getBoolFlag(cmd, "testing", false)
 
 	architecture := getStringFlag(cmd, "arch", "x86_64")
+	cfg.Architecture = architecture
+	cfg.explicitArchDir, _ = cmd.Flags().GetString("repo-arch-dir")
 
 	// Detecting targetRepo and name of DB
 	if cfg.TestingMode {
@@ -249,9 +419,27 @@ func NewConfig(cmd *cobra.Command) (*Config, error) {
 	if cfg.GitLabToken == "" {
 		cfg.GitLabToken = os.Getenv("GITLAB_TOKEN")
 	}
+	if cfg.GitLabToken == "" {
+		if token, ok := storedGitLabToken(defaultGitLabHost); ok {
+			cfg.GitLabToken = token
+			cfg.debugLog("Using GitLab token from `auth login` for %s", defaultGitLabHost)
+		}
+	}
 
-	cfg.Debug, _ = cmd.Flags().GetBool("debug")
-	cfg.Verbose, _ = cmd.Flags().GetBool("verbose")
+	cfg.Debug = getBoolFlag(cmd, "debug", false)
+	cfg.Verbose = getBoolFlag(cmd, "verbose", false)
+
+	cfg.SigningKey = getStringFlag(cmd, "signing-key", "")
+	cfg.RequireSignatures, _ = cmd.Flags().GetBool("verify-sigs")
+
+	cfg.MaxParallel, _ = cmd.Flags().GetInt("max-parallel")
+	if cfg.MaxParallel <= 0 {
+		cfg.MaxParallel = 4
+	}
+
+	cfg.Dashboard, _ = cmd.Flags().GetBool("dashboard")
+	cfg.TemplateDir = getStringFlag(cmd, "template-dir", "")
+	cfg.PromoteAfterDays, _ = cmd.Flags().GetInt("promote-after")
 
 	cfg.debugLog("Config initialized: repo=%s, db=%s, target=%s, dir=%s",
 		cfg.RepoName, cfg.dbBaseName, cfg.targetRepo, cfg.RepoArchDir)
@@ -273,6 +461,11 @@ func (pm *PackageManager) syncPackages(packagesConfig *PackagesConfig) error {
 		return fmt.Errorf("failed to fetch remote URL packages: %w", err)
 	}
 
+	mirrorPackages, err := pm.fetchPacmanMirrorPackages(packagesConfig.PacmanMirrors)
+	if err != nil {
+		return fmt.Errorf("failed to fetch pacman mirror packages: %w", err)
+	}
+
 	allRemotePackages := make(map[string]RemotePackage)
 	for _, pkg := range gitlabPackages {
 		allRemotePackages[pkg.Filename] = pkg
@@ -280,6 +473,9 @@ func (pm *PackageManager) syncPackages(packagesConfig *PackagesConfig) error {
 	for _, pkg := range remotePackages {
 		allRemotePackages[pkg.Filename] = pkg
 	}
+	for _, pkg := range mirrorPackages {
+		allRemotePackages[pkg.Filename] = pkg
+	}
 
 	pm.config.infoLog("Found %d packages total", len(allRemotePackages))
 
@@ -287,21 +483,56 @@ func (pm *PackageManager) syncPackages(packagesConfig *PackagesConfig) error {
 		return fmt.Errorf("failed to remove orphaned packages: %w", err)
 	}
 
-	if err := pm.downloadNewPackages(allRemotePackages); err != nil {
-		return fmt.Errorf("failed to download new packages: %w", err)
-	}
+	// A download failure here only means that one package is missing from
+	// disk; it doesn't invalidate the packages that did succeed. Keep
+	// regenerating the db/JSON against whatever's actually on disk and
+	// surface the download error last, so one flaky file doesn't leave the
+	// repo-add database and packages.json stale for everything else.
+	downloadErr := pm.downloadNewPackages(allRemotePackages)
 
 	if err := pm.updateRepoDatabase(); err != nil {
 		return fmt.Errorf("failed to update repository database: %w", err)
 	}
 
-	if err := pm.generatePackagesJSON(); err != nil {
+	if err := pm.generatePackagesJSON(allRemotePackages); err != nil {
 		return fmt.Errorf("failed to generate packages.json: %w", err)
 	}
 
+	if downloadErr != nil {
+		return fmt.Errorf("failed to download new packages: %w", downloadErr)
+	}
+
 	return nil
 }
 
+// packageArch extracts the architecture from a pacman package filename of
+// the form <name>-<ver>-<rel>-<arch>.pkg.tar.zst.
+func packageArch(filename string) string {
+	base := strings.TrimSuffix(filename, ".pkg.tar.zst")
+	parts := strings.Split(base, "-")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
+
+func containsString(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteURLHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "remote"
+	}
+	return parsed.Host
+}
+
 func containsRepository(repoList, target string) bool {
 	for _, repo := range strings.Split(repoList, ";") {
 		if strings.TrimSpace(repo) == target {
@@ -330,11 +561,15 @@ func (pm *PackageManager) fetchGitLabPackages(projects []GitLabProject) ([]Remot
 		if !project.Enabled || !containsRepository(project.Repository, targetRepo) {
 			continue
 		}
+		if len(project.Arches) > 0 && !containsString(project.Arches, pm.config.Architecture) {
+			pm.config.verboseLog("Skipping project %s: not published for arch %s", project.Name, pm.config.Architecture)
+			continue
+		}
 
 		pm.config.verboseLog("Fetching releases for project: %s (%s)", project.Name, project.ID)
 
 		var allReleases []*gitlab.Release
-		page := 1
+		var page int64 = 1
 		for {
 			listOptions := &gitlab.ListReleasesOptions{
 				ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
@@ -362,6 +597,21 @@ func (pm *PackageManager) fetchGitLabPackages(projects []GitLabProject) ([]Remot
 			if targetRepo == "testing" {
 				releaseToUse = allReleases[0]
 				pm.config.verboseLog("Using LATEST version for testing: %s", releaseToUse.Name)
+			} else if targetRepo == "stable" && pm.config.PromoteAfterDays > 0 && pm.store != nil {
+				promoted, err := pm.selectPromotedRelease(allReleases)
+				if err != nil {
+					pm.config.debugLog("Promotion lookup failed for %s, falling back to previous-release rule: %v", project.Name, err)
+				}
+				if promoted != nil {
+					releaseToUse = promoted
+					pm.config.verboseLog("Promoting %s to stable after %d+ days in testing", releaseToUse.Name, pm.config.PromoteAfterDays)
+				} else if len(allReleases) > 1 {
+					releaseToUse = allReleases[1]
+					pm.config.verboseLog("No release has aged %d days in testing yet; using PREVIOUS version for stable: %s", pm.config.PromoteAfterDays, releaseToUse.Name)
+				} else {
+					pm.config.verboseLog("Skipping project %s for stable (no promotable release)", project.Name)
+					continue
+				}
 			} else if targetRepo == "stable" && len(allReleases) > 1 {
 				releaseToUse = allReleases[1]
 				pm.config.verboseLog("Using PREVIOUS version for stable: %s", releaseToUse.Name)
@@ -374,12 +624,25 @@ func (pm *PackageManager) fetchGitLabPackages(projects []GitLabProject) ([]Remot
 			pm.config.verboseLog("Using LATEST version (single repo): %s", releaseToUse.Name)
 		}
 
+		sigURLs := make(map[string]string)
+		for _, asset := range releaseToUse.Assets.Links {
+			if strings.HasSuffix(asset.Name, ".sig") && strings.HasPrefix(asset.URL, "https") {
+				sigURLs[strings.TrimSuffix(asset.Name, ".sig")] = asset.URL
+			}
+		}
+
 		for _, asset := range releaseToUse.Assets.Links {
 			if strings.HasSuffix(asset.Name, ".pkg.tar.zst") && strings.HasPrefix(asset.URL, "https") {
+				if assetArch := packageArch(asset.Name); assetArch != "any" && assetArch != pm.config.Architecture {
+					continue
+				}
 				packages = append(packages, RemotePackage{
 					Filename:   asset.Name,
 					URL:        asset.URL,
 					Repository: targetRepo,
+					SigURL:     sigURLs[asset.Name],
+					Source:     project.Name,
+					ReleaseTag: releaseToUse.TagName,
 				})
 				pm.config.verboseLog("Added package: %s from %s", asset.Name, releaseToUse.Name)
 			}
@@ -407,6 +670,8 @@ func (pm *PackageManager) fetchRemoteURLPackages(remoteURLs []RemoteURL) ([]Remo
 					Filename:   filename,
 					URL:        cleanURL,
 					Repository: remote.Repository,
+					SigURL:     cleanURL + ".sig",
+					Source:     remoteURLHost(cleanURL),
 				})
 				pm.config.verboseLog("Added remote package: %s", filename)
 			}
@@ -424,6 +689,7 @@ func (pm *PackageManager) removeOrphanedPackages(remotePackages map[string]Remot
 	}
 
 	removedCount := 0
+	var removedNames []string
 	for _, file := range files {
 		if !file.IsDir() && strings.HasSuffix(file.Name(), ".pkg.tar.zst") {
 			if _, exists := remotePackages[file.Name()]; !exists {
@@ -432,64 +698,33 @@ func (pm *PackageManager) removeOrphanedPackages(remotePackages map[string]Remot
 				if err := os.Remove(filePath); err != nil {
 					return fmt.Errorf("failed to remove orphaned package %s: %w", file.Name(), err)
 				}
+				removedNames = append(removedNames, file.Name())
 				removedCount++
 			}
 		}
 	}
 
-	if removedCount > 0 {
-		pm.config.infoLog("Removed %d orphaned packages", removedCount)
-	}
-
-	return nil
-}
-
-func (pm *PackageManager) downloadNewPackages(remotePackages map[string]RemotePackage) error {
-	downloadedCount := 0
-	for filename, pkg := range remotePackages {
-		localPath := filepath.Join(pm.config.RepoArchDir, filename)
-		if _, err := os.Stat(localPath); os.IsNotExist(err) {
-			pm.config.verboseLog("Downloading package: %s from %s", filename, pkg.URL)
-			if err := pm.downloadFile(localPath, pkg.URL); err != nil {
-				pm.config.debugLog("Failed to download %s: %v", filename, err)
-				os.Remove(localPath)
-				continue
-			}
-			downloadedCount++
+	if state, err := loadDownloadState(pm.config); err == nil {
+		if state.LastRun == nil {
+			state.LastRun = make(map[string]RunSummary)
+		}
+		summary := state.LastRun[pm.config.runKey()]
+		summary.Removed = removedNames
+		state.LastRun[pm.config.runKey()] = summary
+		if err := state.save(pm.config); err != nil {
+			pm.config.debugLog("Failed to persist orphan state: %v", err)
 		}
 	}
 
-	if downloadedCount > 0 {
-		pm.config.infoLog("Downloaded %d new packages", downloadedCount)
+	if removedCount > 0 {
+		pm.config.infoLog("Removed %d orphaned packages", removedCount)
 	}
 
 	return nil
 }
 
-func (pm *PackageManager) downloadFile(filepath string, url string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to get %s: %w", url, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status: %s", resp.Status)
-	}
-
-	out, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filepath, err)
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to write file %s: %w", filepath, err)
-	}
-
-	return nil
-}
+// downloadNewPackages and downloadFile live in download.go, which also
+// implements the parallel worker pool, retry/resume and checksum caching.
 
 func (pm *PackageManager) updateRepoDatabase() error {
 	originalDir, err := os.Getwd()
@@ -527,8 +762,21 @@ func (pm *PackageManager) updateRepoDatabase() error {
 	}
 
 	if len(matches) > 0 {
+		if pm.config.SigningKey != "" {
+			for _, pkgFile := range matches {
+				if err := pm.signPackageFile(pkgFile); err != nil {
+					return fmt.Errorf("failed to sign package %s: %w", pkgFile, err)
+				}
+			}
+		}
+
 		dbName := pm.config.getDBName()
-		args := append([]string{dbName}, matches...)
+		args := []string{}
+		if pm.config.SigningKey != "" {
+			args = append(args, "-s", "-v", "-k", pm.config.SigningKey)
+		}
+		args = append(args, dbName)
+		args = append(args, matches...)
 		cmd := exec.Command("repo-add", args...)
 		if pm.config.Debug || pm.config.Verbose {
 			cmd.Stdout = os.Stdout
@@ -550,10 +798,21 @@ func (pm *PackageManager) updateRepoDatabase() error {
 	os.Symlink(pm.config.getDBName(), pm.config.dbBaseName+".db")
 	os.Symlink(pm.config.getFilesName(), pm.config.dbBaseName+".files")
 
+	if pm.config.SigningKey != "" {
+		os.Remove(pm.config.dbBaseName + ".db.sig")
+		os.Remove(pm.config.dbBaseName + ".files.sig")
+		if _, err := os.Stat(pm.config.getDBName() + ".sig"); err == nil {
+			os.Symlink(pm.config.getDBName()+".sig", pm.config.dbBaseName+".db.sig")
+		}
+		if _, err := os.Stat(pm.config.getFilesName() + ".sig"); err == nil {
+			os.Symlink(pm.config.getFilesName()+".sig", pm.config.dbBaseName+".files.sig")
+		}
+	}
+
 	return nil
 }
 
-func (pm *PackageManager) generatePackagesJSON() error {
+func (pm *PackageManager) generatePackagesJSON(remotePackages map[string]RemotePackage) error {
 	var packageList []PackageInfo
 
 	files, err := os.ReadDir(pm.config.RepoArchDir)
@@ -572,16 +831,22 @@ func (pm *PackageManager) generatePackagesJSON() error {
 				continue
 			}
 			pkgInfo.Repository = targetRepo
+			if remote, ok := remotePackages[file.Name()]; ok {
+				pkgInfo.Source = remote.Source
+				pkgInfo.SourceURL = remote.URL
+			}
 			packageList = append(packageList, *pkgInfo)
 		}
 	}
 
+	pm.recordPackageHistory(packageList, remotePackages)
+
 	jsonData, err := json.MarshalIndent(packageList, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	apiFileName := fmt.Sprintf("%s.json", targetRepo)
+	apiFileName := pm.config.apiFileName()
 	outputPath := filepath.Join(pm.config.APIDir, apiFileName)
 
 	err = os.WriteFile(outputPath, jsonData, 0644)
@@ -639,6 +904,10 @@ func (pm *PackageManager) extractPackageInfo(pkgPath string) (*PackageInfo, erro
 	info.Size = fmt.Sprintf("%d", fileInfo.Size())
 	info.Modified = fileInfo.ModTime().Format("2006-01-02 15:04:05")
 
+	if _, err := os.Stat(pkgPath + ".sig"); err == nil {
+		info.Signed = true
+	}
+
 	return info, nil
 }
 
@@ -674,8 +943,10 @@ func readPackagesConfig() (*PackagesConfig, error) {
 			return nil, fmt.Errorf("failed to create default config: %w", err)
 		}
 
-		fmt.Printf("Created default config file: %s\n", configFile)
-		fmt.Println("Please edit the config file and run the command again.")
+		// Human-readable only: goes to stderr so it never corrupts a
+		// `--output json` stdout stream (e.g. `status --output json`).
+		fmt.Fprintf(os.Stderr, "Created default config file: %s\n", configFile)
+		fmt.Fprintln(os.Stderr, "Please edit the config file and run the command again.")
 		return defaultConfig, nil
 	}
 
@@ -692,18 +963,16 @@ func readPackagesConfig() (*PackagesConfig, error) {
 	return &config, nil
 }
 
-func runPackageManagement(cfg *Config) error {
+func runPackageManagement(cfg *Config, packagesConfig *PackagesConfig) error {
 	cfg.debugLog("Starting with config: %+v", cfg)
 
-	packagesConfig, err := readPackagesConfig()
-	if err != nil {
-		return fmt.Errorf("failed to read packages configuration: %w", err)
-	}
-
 	pm, err := NewPackageManager(cfg)
 	if err != nil {
 		return err
 	}
+	if pm.store != nil {
+		defer pm.store.Close()
+	}
 
 	fileMgr := NewFileManager(cfg)
 
@@ -729,7 +998,26 @@ var RootCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		return runPackageManagement(cfg)
+
+		packagesConfig, err := readPackagesConfig()
+		if err != nil {
+			return fmt.Errorf("failed to read packages configuration: %w", err)
+		}
+
+		arches := resolveArchMatrix(cmd, packagesConfig.Architectures)
+		if err := forEachArch(cmd, arches, cfg, func(archCfg *Config) error {
+			return runPackageManagement(archCfg, packagesConfig)
+		}); err != nil {
+			return err
+		}
+
+		if cfg.Dashboard {
+			if err := generateDashboard(cfg, cfg.TemplateDir, arches); err != nil {
+				return fmt.Errorf("failed to generate dashboard: %w", err)
+			}
+		}
+
+		return nil
 	},
 }
 
@@ -741,7 +1029,29 @@ var statusCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		return showRepositoryStatus(cfg)
+
+		packagesConfig, _ := readPackagesConfig()
+		var fallbackArches []string
+		if packagesConfig != nil {
+			fallbackArches = packagesConfig.Architectures
+		}
+
+		if getStringFlag(cmd, "output", "text") == "json" {
+			arches := resolveArchMatrix(cmd, fallbackArches)
+			report, err := buildStatusReport(cfg, arches)
+			if err != nil {
+				return NewRepoStateError("failed to build status report", err)
+			}
+
+			out, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal status report: %w", err)
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		return forEachArch(cmd, fallbackArches, cfg, showRepositoryStatus)
 	},
 }
 
@@ -787,6 +1097,10 @@ func showRepositoryStatus(cfg *Config) error {
 		cfg.getDBName(),
 		cfg.dbBaseName + ".files",
 		cfg.getFilesName(),
+		cfg.getDBName() + ".sig",
+		cfg.getFilesName() + ".sig",
+		cfg.dbBaseName + ".db.sig",
+		cfg.dbBaseName + ".files.sig",
 	}
 	for _, dbFile := range dbFiles {
 		dbPath := filepath.Join(cfg.RepoArchDir, dbFile)
@@ -803,7 +1117,10 @@ func showRepositoryStatus(cfg *Config) error {
 	fmt.Println()
 
 	fmt.Println("=== API Files ===")
-	apiFiles := []string{"stable.json", "testing.json"}
+	apiFiles := []string{
+		fmt.Sprintf("stable-%s.json", cfg.Architecture),
+		fmt.Sprintf("testing-%s.json", cfg.Architecture),
+	}
 	for _, apiFile := range apiFiles {
 		apiPath := filepath.Join(cfg.APIDir, apiFile)
 		if info, err := os.Stat(apiPath); err == nil {
@@ -845,62 +1162,92 @@ var cleanCmd = &cobra.Command{
 			return err
 		}
 
-		fileMgr := NewFileManager(cfg)
+		packagesConfig, _ := readPackagesConfig()
+		var fallbackArches []string
+		if packagesConfig != nil {
+			fallbackArches = packagesConfig.Architectures
+		}
 
-		fmt.Printf("Starting cleanup mode for %s repository...\n", cfg.getTargetRepo())
-		fmt.Printf("Database: %s\n", cfg.dbBaseName)
+		return forEachArch(cmd, fallbackArches, cfg, cleanArchitecture)
+	},
+}
 
-		if err := fileMgr.removeAllPackages(); err != nil {
-			return fmt.Errorf("failed to remove packages: %w", err)
-		}
+func cleanArchitecture(cfg *Config) error {
+	fileMgr := NewFileManager(cfg)
 
-		if err := fileMgr.removeRepositoryDatabase(); err != nil {
-			return fmt.Errorf("failed to remove repository database: %w", err)
-		}
+	fmt.Printf("Starting cleanup mode for %s repository (%s)...\n", cfg.getTargetRepo(), cfg.Architecture)
+	fmt.Printf("Database: %s\n", cfg.dbBaseName)
 
-		if err := fileMgr.createEmptyPackagesJSON(); err != nil {
-			return fmt.Errorf("failed to create empty packages.json: %w", err)
-		}
+	if err := fileMgr.removeAllPackages(); err != nil {
+		return fmt.Errorf("failed to remove packages: %w", err)
+	}
 
-		fmt.Println("All packages and repository files have been removed successfully.")
-		return nil
-	},
+	if err := fileMgr.removeRepositoryDatabase(); err != nil {
+		return fmt.Errorf("failed to remove repository database: %w", err)
+	}
+
+	if err := fileMgr.createEmptyPackagesJSON(); err != nil {
+		return fmt.Errorf("failed to create empty packages.json: %w", err)
+	}
+
+	fmt.Println("All packages and repository files have been removed successfully.")
+	return nil
 }
 
 func init() {
-	// Root command flags
-	RootCmd.Flags().String("repo-name", "prismlinux", "Repository name")
-	RootCmd.Flags().String("arch", "x86_64", "Target architecture")
+	// Root command flags. Defaults for the settings-backed keys come from
+	// the user/project config (see stringFlagDefault/boolFlagDefault in
+	// configcmd.go) instead of a bare literal, so a configured default
+	// doesn't need to be re-typed on every invocation. --gitlab-token is
+	// deliberately exempted: cobra prints flag defaults in --help, and a
+	// token shouldn't ever be echoed there.
+	RootCmd.Flags().String("repo-name", stringFlagDefault("repo-name", "prismlinux"), "Repository name")
+	RootCmd.Flags().String("arch", stringFlagDefault("arch", "x86_64"), "Target architecture")
 	RootCmd.Flags().String("repo-arch-dir", "", "Architecture-specific repo directory (auto-determined)")
-	RootCmd.Flags().String("api-dir", "api", "API directory for metadata")
+	RootCmd.Flags().String("api-dir", stringFlagDefault("api-dir", "api"), "API directory for metadata")
 	RootCmd.Flags().String("gitlab-token", "", "GitLab token (overrides GITLAB_TOKEN env)")
-	RootCmd.Flags().Bool("testing", false, "Use testing repository instead of stable")
-	RootCmd.Flags().Bool("debug", false, "Enable debug output")
-	RootCmd.Flags().Bool("verbose", false, "Enable verbose output")
+	RootCmd.Flags().Bool("testing", boolFlagDefault("testing", false), "Use testing repository instead of stable")
+	RootCmd.PersistentFlags().Bool("debug", boolFlagDefault("debug", false), "Enable debug output, and show full error chains")
+	RootCmd.Flags().Bool("verbose", boolFlagDefault("verbose", false), "Enable verbose output")
+	RootCmd.Flags().String("signing-key", "", "GPG key ID to sign the repository database and packages with")
+	RootCmd.Flags().Bool("verify-sigs", false, "Require and verify GPG signatures on downloaded packages")
+	RootCmd.Flags().Int("max-parallel", 4, "Maximum number of packages to download concurrently")
+	RootCmd.Flags().Bool("dashboard", false, "Also render the HTML status dashboard after syncing")
+	RootCmd.Flags().String("template-dir", "", "Directory with index.html.tmpl/package.html.tmpl overrides for the dashboard")
+	RootCmd.Flags().String("arches", "", "Comma-separated architecture matrix (overrides packages_config.yaml and --arch)")
+	RootCmd.Flags().Int("promote-after", 0, "Days a release must spend in testing before promoting it to stable (0 disables, uses the single-previous-release rule)")
+	RootCmd.PersistentFlags().String("output", "text", "Output format for read commands and errors: text or json")
 
 	// Clean command flags
-	cleanCmd.Flags().String("repo-name", "prismlinux", "Repository name")
-	cleanCmd.Flags().String("arch", "x86_64", "Target architecture")
+	cleanCmd.Flags().String("repo-name", stringFlagDefault("repo-name", "prismlinux"), "Repository name")
+	cleanCmd.Flags().String("arch", stringFlagDefault("arch", "x86_64"), "Target architecture")
 	cleanCmd.Flags().String("repo-arch-dir", "", "Architecture-specific repo directory (auto-determined)")
-	cleanCmd.Flags().String("api-dir", "api", "API directory for metadata")
-	cleanCmd.Flags().Bool("testing", false, "Clean testing repository instead of stable")
-	cleanCmd.Flags().Bool("debug", false, "Enable debug output")
-	cleanCmd.Flags().Bool("verbose", false, "Enable verbose output")
+	cleanCmd.Flags().String("api-dir", stringFlagDefault("api-dir", "api"), "API directory for metadata")
+	cleanCmd.Flags().Bool("testing", boolFlagDefault("testing", false), "Clean testing repository instead of stable")
+	cleanCmd.Flags().Bool("debug", boolFlagDefault("debug", false), "Enable debug output")
+	cleanCmd.Flags().Bool("verbose", boolFlagDefault("verbose", false), "Enable verbose output")
+	cleanCmd.Flags().String("arches", "", "Comma-separated architecture matrix (overrides packages_config.yaml and --arch)")
 
 	// Status command flags
-	statusCmd.Flags().String("repo-name", "prismlinux", "Repository name")
-	statusCmd.Flags().String("arch", "x86_64", "Target architecture")
+	statusCmd.Flags().String("repo-name", stringFlagDefault("repo-name", "prismlinux"), "Repository name")
+	statusCmd.Flags().String("arch", stringFlagDefault("arch", "x86_64"), "Target architecture")
 	statusCmd.Flags().String("repo-arch-dir", "", "Architecture-specific repo directory (auto-determined)")
-	statusCmd.Flags().String("api-dir", "api", "API directory for metadata")
-	statusCmd.Flags().Bool("testing", false, "Show testing repository status")
+	statusCmd.Flags().String("api-dir", stringFlagDefault("api-dir", "api"), "API directory for metadata")
+	statusCmd.Flags().Bool("testing", boolFlagDefault("testing", false), "Show testing repository status")
+	statusCmd.Flags().String("arches", "", "Comma-separated architecture matrix (overrides packages_config.yaml and --arch)")
 
 	RootCmd.AddCommand(cleanCmd)
 	RootCmd.AddCommand(statusCmd)
 }
 
 func main() {
-	if err := RootCmd.Execute(); err != nil {
-		fmt.Println("Error:", err)
+	registerDynamicCompletions(RootCmd)
+
+	cmd, err := RootCmd.ExecuteC()
+	if err != nil {
+		debug, _ := cmd.Flags().GetBool("debug")
+		output, _ := cmd.Flags().GetString("output")
+		printError(err, debug, output == "json")
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,230 @@
+// Package settings loads the persistent defaults shared by every
+// subcommand (repo-name, arch, api-dir, testing, debug, verbose,
+// gitlab-token) from a user-level and an optional project-local config
+// file, so the same flags don't need to be re-typed on every invocation.
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigName is the project-local override file, checked in the
+// current working directory.
+const ProjectConfigName = ".prismrepo.yml"
+
+// Settings mirrors the flags most subcommands share. Bool fields are
+// pointers so "not set in this file" is distinguishable from "set to false".
+type Settings struct {
+	RepoName     string `yaml:"repo_name,omitempty"`
+	Architecture string `yaml:"arch,omitempty"`
+	APIDir       string `yaml:"api_dir,omitempty"`
+	Testing      *bool  `yaml:"testing,omitempty"`
+	Debug        *bool  `yaml:"debug,omitempty"`
+	Verbose      *bool  `yaml:"verbose,omitempty"`
+	GitLabToken  string `yaml:"gitlab_token,omitempty"`
+}
+
+// keys is every config key, in the order `list` should print them.
+var keys = []string{"repo-name", "arch", "api-dir", "testing", "debug", "verbose", "gitlab-token"}
+
+// Keys returns every recognized config key.
+func Keys() []string {
+	return append([]string(nil), keys...)
+}
+
+// UserConfigPath is where `config set`/`config edit` persist settings:
+// ~/.config/prismlinux/repository/config.yml.
+func UserConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "prismlinux", "repository", "config.yml"), nil
+}
+
+func readFile(path string) (*Settings, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Settings{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var s Settings
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Load reads the user-level config, then overlays a project-local
+// .prismrepo.yml if one exists in the current directory.
+func Load() (*Settings, error) {
+	userPath, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := readFile(userPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(ProjectConfigName); err == nil {
+		project, err := readFile(ProjectConfigName)
+		if err != nil {
+			return nil, err
+		}
+		merged = merge(merged, project)
+	}
+
+	return merged, nil
+}
+
+// LoadUser reads only the user-level config file, ignoring any
+// project-local override. Used by `config set`/`config get` so edits always
+// target the file a user actually owns.
+func LoadUser() (*Settings, error) {
+	userPath, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return readFile(userPath)
+}
+
+// Save writes s to the user-level config file with normal (0644)
+// permissions; unlike hosts.yml it holds no secret beyond an optional token
+// the user already chose to put here.
+func (s *Settings) Save() error {
+	path, err := UserConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// merge returns a new Settings with every non-zero field of overlay taking
+// precedence over base.
+func merge(base, overlay *Settings) *Settings {
+	result := *base
+
+	if overlay.RepoName != "" {
+		result.RepoName = overlay.RepoName
+	}
+	if overlay.Architecture != "" {
+		result.Architecture = overlay.Architecture
+	}
+	if overlay.APIDir != "" {
+		result.APIDir = overlay.APIDir
+	}
+	if overlay.Testing != nil {
+		result.Testing = overlay.Testing
+	}
+	if overlay.Debug != nil {
+		result.Debug = overlay.Debug
+	}
+	if overlay.Verbose != nil {
+		result.Verbose = overlay.Verbose
+	}
+	if overlay.GitLabToken != "" {
+		result.GitLabToken = overlay.GitLabToken
+	}
+
+	return &result
+}
+
+// Get returns the string form of key's value and whether it was set.
+func (s *Settings) Get(key string) (string, bool) {
+	switch key {
+	case "repo-name":
+		return s.RepoName, s.RepoName != ""
+	case "arch":
+		return s.Architecture, s.Architecture != ""
+	case "api-dir":
+		return s.APIDir, s.APIDir != ""
+	case "testing":
+		return boolString(s.Testing)
+	case "debug":
+		return boolString(s.Debug)
+	case "verbose":
+		return boolString(s.Verbose)
+	case "gitlab-token":
+		return s.GitLabToken, s.GitLabToken != ""
+	default:
+		return "", false
+	}
+}
+
+func boolString(b *bool) (string, bool) {
+	if b == nil {
+		return "", false
+	}
+	if *b {
+		return "true", true
+	}
+	return "false", true
+}
+
+// Set parses value and stores it under key, returning an error for an
+// unrecognized key or an invalid bool.
+func (s *Settings) Set(key, value string) error {
+	switch key {
+	case "repo-name":
+		s.RepoName = value
+	case "arch":
+		s.Architecture = value
+	case "api-dir":
+		s.APIDir = value
+	case "testing":
+		b, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		s.Testing = &b
+	case "debug":
+		b, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		s.Debug = &b
+	case "verbose":
+		b, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		s.Verbose = &b
+	case "gitlab-token":
+		s.GitLabToken = value
+	default:
+		return fmt.Errorf("unknown config key: %s", key)
+	}
+	return nil
+}
+
+func parseBool(value string) (bool, error) {
+	switch value {
+	case "true", "yes", "1":
+		return true, nil
+	case "false", "no", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid bool value %q (use true/false)", value)
+	}
+}
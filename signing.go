@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// signPackageFile detach-signs a package file with the configured signing
+// key, unless a companion .sig is already present (e.g. fetched alongside
+// the package from a GitLab release).
+func (pm *PackageManager) signPackageFile(pkgFile string) error {
+	sigPath := pkgFile + ".sig"
+	if _, err := os.Stat(sigPath); err == nil {
+		pm.config.verboseLog("Signature already present for %s, skipping gpg sign", pkgFile)
+		return nil
+	}
+
+	cmd := exec.Command("gpg", "--batch", "--yes", "--local-user", pm.config.SigningKey, "--detach-sign", pkgFile)
+	if pm.config.Debug || pm.config.Verbose {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("gpg --detach-sign failed for %s: %w", pkgFile, err)
+	}
+
+	pm.config.verboseLog("Signed package: %s", pkgFile)
+	return nil
+}
+
+// fetchAndVerifySignature fetches the detached signature for a freshly
+// downloaded package (when the remote advertised one, or verification is
+// required) and verifies it with gpg. Callers are expected to remove the
+// package and its signature on error, mirroring the orphan-cleanup path.
+func (pm *PackageManager) fetchAndVerifySignature(localPath string, pkg RemotePackage) error {
+	sigURL := pkg.SigURL
+	if sigURL == "" {
+		if pm.config.RequireSignatures {
+			return fmt.Errorf("no signature available for %s but --verify-sigs is set", pkg.Filename)
+		}
+		return nil
+	}
+
+	sigPath := localPath + ".sig"
+	if err := pm.downloadFile(sigPath, sigURL); err != nil {
+		if pm.config.RequireSignatures {
+			return fmt.Errorf("failed to download signature for %s: %w", pkg.Filename, err)
+		}
+		pm.config.debugLog("No signature found at %s, continuing unsigned: %v", sigURL, err)
+		return nil
+	}
+
+	if err := verifyDetachedSignature(localPath, sigPath); err != nil {
+		return fmt.Errorf("signature verification failed for %s: %w", pkg.Filename, err)
+	}
+
+	pm.config.verboseLog("Verified signature for %s", pkg.Filename)
+	return nil
+}
+
+// verifyDetachedSignature runs gpg --verify against a package and its
+// detached .sig file.
+func verifyDetachedSignature(pkgPath, sigPath string) error {
+	cmd := exec.Command("gpg", "--verify", sigPath, pkgPath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("gpg --verify failed: %w (%s)", err, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.com/prismlinux/repository/internal/testhelper"
+)
+
+// withIsolatedHome returns a HOME=... env override pointed at a fresh
+// directory, so `status`/`clean` never read or write a real user's
+// ~/.config/prismlinux/repository files.
+func withIsolatedHome(t *testing.T) []string {
+	t.Helper()
+	return []string{"HOME=" + t.TempDir()}
+}
+
+func TestStatusJSONReportsPackagesAndDanglingFiles(t *testing.T) {
+	bin := testhelper.BuildCLI(t)
+	dir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dir, "x86_64"), 0755); err != nil {
+		t.Fatalf("failed to create repo-arch-dir: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "api"), 0755); err != nil {
+		t.Fatalf("failed to create api-dir: %v", err)
+	}
+
+	// example-1.0.0-1-x86_64.pkg.tar.zst has no matching api/stable-x86_64.json
+	// entry, so it should be reported as dangling.
+	danglingPkg := "example-1.0.0-1-x86_64.pkg.tar.zst"
+	pkgPath := filepath.Join(dir, "x86_64", danglingPkg)
+	if err := os.WriteFile(pkgPath, []byte("fake package"), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", pkgPath, err)
+	}
+
+	result := testhelper.Run(t, bin, dir, withIsolatedHome(t),
+		"status", "--arch", "x86_64", "--api-dir", "api", "--output", "json")
+	if result.ExitCode != 0 {
+		t.Fatalf("status exited %d, stderr:\n%s", result.ExitCode, result.Stderr)
+	}
+
+	var report StatusReport
+	if err := json.Unmarshal([]byte(result.Stdout), &report); err != nil {
+		t.Fatalf("failed to parse status JSON: %v\noutput:\n%s", err, result.Stdout)
+	}
+
+	var stableX86 *RepoArchStatus
+	for i := range report.Repos {
+		if report.Repos[i].Repo == "stable" && report.Repos[i].Architecture == "x86_64" {
+			stableX86 = &report.Repos[i]
+		}
+	}
+	if stableX86 == nil {
+		t.Fatalf("no stable/x86_64 entry in report: %+v", report.Repos)
+	}
+	if stableX86.PackageCount != 0 {
+		t.Errorf("expected package_count 0 (no api metadata yet), got %d", stableX86.PackageCount)
+	}
+	if len(stableX86.DanglingFiles) != 1 || stableX86.DanglingFiles[0] != danglingPkg {
+		t.Errorf("expected dangling_files [%s], got %v", danglingPkg, stableX86.DanglingFiles)
+	}
+}
+
+func TestStatusTextModePrintsArchDirAndAPIDir(t *testing.T) {
+	bin := testhelper.BuildCLI(t)
+	dir := t.TempDir()
+
+	result := testhelper.Run(t, bin, dir, withIsolatedHome(t),
+		"status", "--arch", "aarch64", "--api-dir", "api")
+	if result.ExitCode != 0 {
+		t.Fatalf("status exited %d, stderr:\n%s", result.ExitCode, result.Stderr)
+	}
+
+	for _, want := range []string{"Architecture directory: aarch64", "API directory: api"} {
+		if !strings.Contains(result.Stdout, want) {
+			t.Errorf("text output missing %q, got:\n%s", want, result.Stdout)
+		}
+	}
+}
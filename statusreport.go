@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RepoArchStatus is one (repo, arch) pair's slice of the machine-readable
+// status report: `status --output json`.
+type RepoArchStatus struct {
+	Repo          string   `json:"repo"`
+	Architecture  string   `json:"architecture"`
+	PackageCount  int      `json:"package_count"`
+	LastUpdated   string   `json:"last_updated,omitempty"`
+	DanglingFiles []string `json:"dangling_files,omitempty"`
+}
+
+// StatusReport is the stable JSON schema `status --output json` emits, so
+// CI dashboards can consume it without scraping text output.
+type StatusReport struct {
+	Repos       []RepoArchStatus `json:"repos"`
+	StableOnly  []string         `json:"stable_only,omitempty"`
+	TestingOnly []string         `json:"testing_only,omitempty"`
+}
+
+// buildStatusReport inspects both the stable and testing repos across every
+// architecture in arches, regardless of which one --testing selected, so the
+// stable/testing diff is always complete.
+func buildStatusReport(cfg *Config, arches []string) (*StatusReport, error) {
+	report := &StatusReport{}
+	stableNames := make(map[string]bool)
+	testingNames := make(map[string]bool)
+
+	for _, arch := range arches {
+		for _, target := range []string{"stable", "testing"} {
+			targetCfg := cfg.forTargetAndArch(target, arch)
+
+			status := RepoArchStatus{Repo: target, Architecture: arch}
+
+			known := make(map[string]bool)
+			apiPath := filepath.Join(targetCfg.APIDir, targetCfg.apiFileName())
+			if data, err := os.ReadFile(apiPath); err == nil {
+				var packages []PackageInfo
+				if err := json.Unmarshal(data, &packages); err != nil {
+					return nil, fmt.Errorf("failed to parse %s: %w", apiPath, err)
+				}
+				status.PackageCount = len(packages)
+
+				for _, pkg := range packages {
+					known[pkg.Filename] = true
+					if target == "stable" {
+						stableNames[pkg.Name] = true
+					} else {
+						testingNames[pkg.Name] = true
+					}
+				}
+
+				if info, err := os.Stat(apiPath); err == nil {
+					status.LastUpdated = info.ModTime().UTC().Format(time.RFC3339)
+				}
+			}
+
+			if entries, err := os.ReadDir(targetCfg.RepoArchDir); err == nil {
+				for _, entry := range entries {
+					if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".pkg.tar.zst") && !known[entry.Name()] {
+						status.DanglingFiles = append(status.DanglingFiles, entry.Name())
+					}
+				}
+			}
+
+			report.Repos = append(report.Repos, status)
+		}
+	}
+
+	for name := range stableNames {
+		if !testingNames[name] {
+			report.StableOnly = append(report.StableOnly, name)
+		}
+	}
+	for name := range testingNames {
+		if !stableNames[name] {
+			report.TestingOnly = append(report.TestingOnly, name)
+		}
+	}
+	sort.Strings(report.StableOnly)
+	sort.Strings(report.TestingOnly)
+
+	return report, nil
+}
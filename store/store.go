@@ -0,0 +1,148 @@
+// Package store persists a history of every package observed across sync
+// runs, so the repository can answer "what versions of X have we shipped,
+// and when" without re-walking the GitLab API.
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS package_history (
+	repo        TEXT NOT NULL,
+	arch        TEXT NOT NULL,
+	name        TEXT NOT NULL,
+	version     TEXT NOT NULL,
+	filename    TEXT NOT NULL,
+	sha256      TEXT NOT NULL,
+	source_url  TEXT,
+	release_tag TEXT,
+	first_seen  TIMESTAMP NOT NULL,
+	last_seen   TIMESTAMP NOT NULL,
+	PRIMARY KEY (repo, arch, name, version)
+);
+`
+
+// Entry is one observed (repo, arch, name, version) tuple.
+type Entry struct {
+	Repo       string
+	Arch       string
+	Name       string
+	Version    string
+	Filename   string
+	SHA256     string
+	SourceURL  string
+	ReleaseTag string
+	FirstSeen  time.Time
+	LastSeen   time.Time
+}
+
+// Store wraps a SQLite-backed package_history table.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if necessary) the SQLite database at path.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store at %s: %w", path, err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize store schema: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record upserts an observation, updating last_seen on an existing
+// (repo, arch, name, version) and preserving the original first_seen.
+func (s *Store) Record(e Entry, now time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO package_history (repo, arch, name, version, filename, sha256, source_url, release_tag, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(repo, arch, name, version) DO UPDATE SET
+			filename = excluded.filename,
+			sha256 = excluded.sha256,
+			source_url = excluded.source_url,
+			release_tag = excluded.release_tag,
+			last_seen = excluded.last_seen
+	`, e.Repo, e.Arch, e.Name, e.Version, e.Filename, e.SHA256, e.SourceURL, e.ReleaseTag, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to record %s/%s %s %s: %w", e.Repo, e.Arch, e.Name, e.Version, err)
+	}
+	return nil
+}
+
+// History returns every version observed for name, oldest first.
+func (s *Store) History(repo, arch, name string) ([]Entry, error) {
+	rows, err := s.db.Query(`
+		SELECT repo, arch, name, version, filename, sha256, source_url, release_tag, first_seen, last_seen
+		FROM package_history
+		WHERE repo = ? AND arch = ? AND name = ?
+		ORDER BY first_seen ASC
+	`, repo, arch, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history for %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.Repo, &e.Arch, &e.Name, &e.Version, &e.Filename, &e.SHA256, &e.SourceURL, &e.ReleaseTag, &e.FirstSeen, &e.LastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan history row: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// PreviousVersion returns the version that immediately preceded
+// currentVersion for (repo, arch, name), or "" if there isn't one.
+// PreviousVersion returns the most recently recorded version of name that
+// isn't currentVersion, i.e. what it's being upgraded (or downgraded) from.
+// It's safe to call before Record has inserted currentVersion's own entry,
+// which is the order recordPackageHistory uses.
+func (s *Store) PreviousVersion(repo, arch, name, currentVersion string) (string, error) {
+	entries, err := s.History(repo, arch, name)
+	if err != nil {
+		return "", err
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Version != currentVersion {
+			return entries[i].Version, nil
+		}
+	}
+	return "", nil
+}
+
+// FirstSeenInRepo returns when a release tag was first observed in repo, for
+// "promote to stable after N days in testing" logic. ok is false if the tag
+// has never been seen there.
+func (s *Store) FirstSeenInRepo(repo, arch, releaseTag string) (seenAt time.Time, ok bool, err error) {
+	row := s.db.QueryRow(`
+		SELECT MIN(first_seen) FROM package_history
+		WHERE repo = ? AND arch = ? AND release_tag = ?
+	`, repo, arch, releaseTag)
+
+	var t sql.NullTime
+	if err := row.Scan(&t); err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to look up first-seen for tag %s: %w", releaseTag, err)
+	}
+	if !t.Valid {
+		return time.Time{}, false, nil
+	}
+	return t.Time, true, nil
+}
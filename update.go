@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"golang.org/x/mod/semver"
+)
+
+// version is the compiled-in release tag, overridden at build time via
+// -ldflags "-X main.version=vX.Y.Z". "dev" means a local, non-release build.
+var version = "dev"
+
+// selfUpdateProjectIDEnv pins the GitLab project this binary's own releases
+// are published under, for both `update` and the background check hook.
+const selfUpdateProjectIDEnv = "PRISMREPO_PROJECT_ID"
+
+// selfUpdateAssetName is the per-platform release asset this binary looks
+// for, e.g. "repository-linux-amd64".
+func selfUpdateAssetName() string {
+	return fmt.Sprintf("repository-%s-%s", runtime.GOOS, runtime.GOARCH)
+}
+
+// latestSelfRelease returns the most recently created release for projectID.
+func latestSelfRelease(pm *PackageManager, projectID string) (*gitlab.Release, error) {
+	releases, _, err := pm.gitlabClient.Releases.ListReleases(projectID, &gitlab.ListReleasesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 20},
+	})
+	if err != nil {
+		return nil, NewNetworkError(fmt.Sprintf("failed to list releases for %s", projectID), err)
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("project %s has no releases", projectID)
+	}
+
+	sortReleasesByDate(releases)
+	return releases[0], nil
+}
+
+// canonicalSemver prefixes v with "v" if it isn't already, since
+// golang.org/x/mod/semver requires that form.
+func canonicalSemver(v string) string {
+	if !strings.HasPrefix(v, "v") {
+		return "v" + v
+	}
+	return v
+}
+
+// isNewerVersion reports whether latestTag is a strictly greater semver than
+// current, so a retag, hotfix reorder, or rollback release on GitLab can
+// never be mistaken for an upgrade. A "dev" build (or any other non-semver
+// current version) is always considered behind, since it has no real tag to
+// compare against.
+func isNewerVersion(current, latestTag string) bool {
+	latest := canonicalSemver(latestTag)
+	if !semver.IsValid(latest) {
+		return false
+	}
+
+	cur := canonicalSemver(current)
+	if !semver.IsValid(cur) {
+		return true
+	}
+
+	return semver.Compare(latest, cur) > 0
+}
+
+// applySelfUpdate downloads the platform asset for release, verifies its
+// checksum sidecar and (if published) its detached GPG signature, then
+// atomically swaps it in for the running executable via a temp file in the
+// same directory followed by rename.
+func applySelfUpdate(pm *PackageManager, release *gitlab.Release) error {
+	assetName := selfUpdateAssetName()
+
+	var assetURL, sigURL string
+	for _, link := range release.Assets.Links {
+		switch link.Name {
+		case assetName:
+			assetURL = link.URL
+		case assetName + ".sig":
+			sigURL = link.URL
+		}
+	}
+	if assetURL == "" {
+		return fmt.Errorf("release %s has no asset named %s for this platform", release.TagName, assetName)
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to resolve running executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(execPath); err == nil {
+		execPath = resolved
+	}
+
+	tmpPath := execPath + ".update"
+	if err := pm.downloadFile(tmpPath, assetURL); err != nil {
+		return NewNetworkError(fmt.Sprintf("failed to download %s", assetURL), err)
+	}
+	defer os.Remove(tmpPath)
+
+	expectedSum, err := pm.fetchSidecarChecksum(assetURL)
+	if err != nil {
+		return fmt.Errorf("no checksum sidecar published for %s: %w", assetName, err)
+	}
+	actualSum, err := sha256File(tmpPath)
+	if err != nil {
+		return err
+	}
+	if actualSum != expectedSum {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", assetName, expectedSum, actualSum)
+	}
+
+	if sigURL != "" {
+		sigPath := tmpPath + ".sig"
+		if err := pm.downloadFile(sigPath, sigURL); err != nil {
+			return fmt.Errorf("failed to download signature for %s: %w", assetName, err)
+		}
+		defer os.Remove(sigPath)
+
+		if err := verifyDetachedSignature(tmpPath, sigPath); err != nil {
+			return fmt.Errorf("signature verification failed for %s: %w", assetName, err)
+		}
+	}
+
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return fmt.Errorf("failed to make %s executable: %w", tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, execPath); err != nil {
+		return fmt.Errorf("failed to swap in the updated binary: %w", err)
+	}
+
+	return nil
+}
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Check for (and with --apply, install) a newer release of this CLI",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := NewConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		projectID := getStringFlag(cmd, "project-id", os.Getenv(selfUpdateProjectIDEnv))
+		if projectID == "" {
+			return NewUsageError("no project to check: pass --project-id or set PRISMREPO_PROJECT_ID", nil)
+		}
+
+		pm, err := NewPackageManager(cfg)
+		if err != nil {
+			return err
+		}
+		if pm.store != nil {
+			defer pm.store.Close()
+		}
+		if pm.gitlabClient == nil {
+			pm.gitlabClient, err = gitlab.NewClient("")
+			if err != nil {
+				return fmt.Errorf("failed to create GitLab client: %w", err)
+			}
+		}
+
+		release, err := latestSelfRelease(pm, projectID)
+		if err != nil {
+			return err
+		}
+
+		if !isNewerVersion(version, release.TagName) {
+			fmt.Printf("Already up to date (%s)\n", version)
+			return nil
+		}
+
+		apply, _ := cmd.Flags().GetBool("apply")
+		if !apply {
+			fmt.Printf("A new version is available: %s (current: %s). Re-run with --apply to install it.\n", release.TagName, version)
+			return nil
+		}
+
+		if err := applySelfUpdate(pm, release); err != nil {
+			return err
+		}
+
+		fmt.Printf("Updated to %s\n", release.TagName)
+		return nil
+	},
+}
+
+// updateCheckStampPath is where maybeNotifyUpdate throttles its background
+// check to at most once per 24h.
+func updateCheckStampPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "prismlinux", "repository", "update-check-timestamp"), nil
+}
+
+// maybeNotifyUpdate is the lightweight background check hooked from
+// RootCmd's PersistentPreRunE: throttled to once per 24h, silent on any
+// failure, and opted out of via --no-update-check or
+// PRISMREPO_NO_UPDATE_CHECK=1.
+func maybeNotifyUpdate(cmd *cobra.Command) {
+	if cmd.Name() == updateCmd.Name() {
+		return
+	}
+	if noCheck, _ := cmd.Flags().GetBool("no-update-check"); noCheck {
+		return
+	}
+	if os.Getenv("PRISMREPO_NO_UPDATE_CHECK") == "1" {
+		return
+	}
+
+	projectID := os.Getenv(selfUpdateProjectIDEnv)
+	if projectID == "" {
+		return
+	}
+
+	stampPath, err := updateCheckStampPath()
+	if err != nil {
+		return
+	}
+	if info, err := os.Stat(stampPath); err == nil && time.Since(info.ModTime()) < 24*time.Hour {
+		return
+	}
+
+	client, err := gitlab.NewClient("")
+	if err != nil {
+		return
+	}
+	pm := &PackageManager{config: &Config{}, gitlabClient: client}
+
+	release, err := latestSelfRelease(pm, projectID)
+
+	if err := os.MkdirAll(filepath.Dir(stampPath), 0755); err == nil {
+		os.WriteFile(stampPath, []byte(time.Now().Format(time.RFC3339)), 0644)
+	}
+
+	if err != nil || release == nil {
+		return
+	}
+
+	if isNewerVersion(version, release.TagName) {
+		fmt.Fprintf(os.Stderr, "A new version of repository is available: %s (current: %s). Run `repository update --apply` to install it.\n", release.TagName, version)
+	}
+}
+
+func init() {
+	updateCmd.Flags().String("repo-name", stringFlagDefault("repo-name", "prismlinux"), "Repository name")
+	updateCmd.Flags().String("arch", stringFlagDefault("arch", "x86_64"), "Target architecture")
+	updateCmd.Flags().String("api-dir", stringFlagDefault("api-dir", "api"), "API directory for metadata")
+	updateCmd.Flags().String("project-id", "", "GitLab project ID this binary's releases are published under (or PRISMREPO_PROJECT_ID)")
+	updateCmd.Flags().Bool("apply", false, "Download and install the newer release")
+
+	RootCmd.PersistentFlags().Bool("no-update-check", false, "Skip the background check for a newer release")
+
+	RootCmd.AddCommand(updateCmd)
+}